@@ -0,0 +1,430 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+const (
+	// maxToolOutput bounds what a single tool call returns to the model
+	maxToolOutput = 8000
+	// maxAgentRounds bounds the tool rounds the model may take per message
+	maxAgentRounds = 10
+	// agentCommandTimeout bounds commands run by the agent; they get no terminal
+	agentCommandTimeout = 2 * time.Minute
+	maxListEntries      = 500
+	maxGrepMatches      = 200
+	// maxGrepFileSize skips large files, which are rarely source code
+	maxGrepFileSize = 1 << 20
+)
+
+const agentPrompt = `You are running in agent mode, with tools that act on the user's machine. The working directory is %s.
+Use the tools to look at real files, command output and git state instead of guessing. Prefer read_file, list_directory, grep and git_status; only use run_command when they are not enough, and never to change anything the user did not ask for.
+The user approves every tool call and may deny it; if that happens, carry on without it. Keep answers short and say what you found.`
+
+// agentTool is a local capability the model may call in agent mode. Tools
+// either run Go code or, when shell is set, a shell command.
+type agentTool struct {
+	Tool
+	run func(args json.RawMessage) (string, error)
+	// shell returns the command a call runs; such calls go through the risk
+	// policy and the audit log
+	shell func(args json.RawMessage) (string, error)
+}
+
+var agentTools = []agentTool{
+	{
+		Tool: Tool{
+			Name:        "read_file",
+			Description: "Read a text file. Long files are truncated.",
+			Parameters: json.RawMessage(`{"type":"object","properties":{
+				"path":{"type":"string","description":"File path, relative to the working directory"}},
+				"required":["path"]}`),
+		},
+		run: readFileTool,
+	},
+	{
+		Tool: Tool{
+			Name:        "list_directory",
+			Description: "List the entries of a directory with their type and size.",
+			Parameters: json.RawMessage(`{"type":"object","properties":{
+				"path":{"type":"string","description":"Directory path, defaults to the working directory"}}}`),
+		},
+		run: listDirectoryTool,
+	},
+	{
+		Tool: Tool{
+			Name:        "grep",
+			Description: "Search files under a directory for a regular expression (RE2 syntax) and return matching lines as file:line: text.",
+			Parameters: json.RawMessage(`{"type":"object","properties":{
+				"pattern":{"type":"string","description":"Regular expression"},
+				"path":{"type":"string","description":"File or directory to search, defaults to the working directory"},
+				"ignore_case":{"type":"boolean"}},
+				"required":["pattern"]}`),
+		},
+		run: grepTool,
+	},
+	{
+		Tool: Tool{
+			Name:        "git_status",
+			Description: "Show the current branch and changed files of the git repository.",
+			Parameters:  json.RawMessage(`{"type":"object","properties":{}}`),
+		},
+		shell: func(json.RawMessage) (string, error) {
+			return "git status --short --branch", nil
+		},
+	},
+	{
+		Tool: Tool{
+			Name: "run_command",
+			Description: "Run a shell command and return its exit code and combined output. " +
+				"It has no terminal or input and is killed after 2 minutes.",
+			Parameters: json.RawMessage(`{"type":"object","properties":{
+				"command":{"type":"string","description":"Shell command"}},
+				"required":["command"]}`),
+		},
+		shell: func(args json.RawMessage) (string, error) {
+			var a struct {
+				Command string `json:"command"`
+			}
+			if err := json.Unmarshal(args, &a); err != nil {
+				return "", err
+			}
+			if strings.TrimSpace(a.Command) == "" {
+				return "", fmt.Errorf("command is required")
+			}
+			return a.Command, nil
+		},
+	},
+}
+
+// agentToolNames lists the tools for flag help and validation
+func agentToolNames() []string {
+	names := make([]string, len(agentTools))
+	for i, tool := range agentTools {
+		names[i] = tool.Name
+	}
+	return names
+}
+
+func lookupAgentTool(name string) *agentTool {
+	for i := range agentTools {
+		if agentTools[i].Name == name {
+			return &agentTools[i]
+		}
+	}
+	return nil
+}
+
+// agent runs the model's tool calls for a REPL, asking the user to approve
+// each one unless the tool is allowlisted
+type agent struct {
+	allowed map[string]bool
+	reader  *bufio.Reader
+	// task is the user message being worked on, for the audit log
+	task string
+	// prompt is added to the system message of the conversation
+	prompt string
+}
+
+// newAgent creates an agent that auto-approves the allowed tools
+func newAgent(allowed []string) (*agent, error) {
+	dir, _ := os.Getwd()
+	a := &agent{
+		allowed: map[string]bool{},
+		reader:  bufio.NewReader(os.Stdin),
+		prompt:  fmt.Sprintf(agentPrompt, dir),
+	}
+	for _, name := range allowed {
+		name = strings.TrimSpace(name)
+		if lookupAgentTool(name) == nil {
+			return nil, fmt.Errorf("unknown tool %q (available: %s)", name, strings.Join(agentToolNames(), ", "))
+		}
+		a.allowed[name] = true
+	}
+	return a, nil
+}
+
+// tools returns the definitions sent to the model
+func (a *agent) tools() []Tool {
+	tools := make([]Tool, len(agentTools))
+	for i, tool := range agentTools {
+		tools[i] = tool.Tool
+	}
+	return tools
+}
+
+// runCall shows a tool call, gets it approved and returns the result for the
+// model. Denied and failed calls return a message saying so.
+func (a *agent) runCall(call ToolCall) string {
+	magenta := color.New(color.FgMagenta, color.Bold)
+	green := color.New(color.FgGreen)
+	yellow := color.New(color.FgYellow, color.Bold)
+	red := color.New(color.FgRed, color.Bold)
+
+	tool := lookupAgentTool(call.Name)
+	if tool == nil {
+		red.Printf("\n🔧 Unknown tool %s\n", call.Name)
+		return fmt.Sprintf("Error: there is no tool named %q", call.Name)
+	}
+
+	args := json.RawMessage(call.Arguments)
+	if strings.TrimSpace(call.Arguments) == "" {
+		args = json.RawMessage("{}")
+	}
+	if !json.Valid(args) {
+		red.Printf("\n🔧 %s with invalid arguments\n", call.Name)
+		return "Error: the arguments are not a valid JSON object"
+	}
+
+	var command string
+	if tool.shell != nil {
+		var err error
+		if command, err = tool.shell(args); err != nil {
+			red.Printf("\n🔧 %s: %v\n", call.Name, err)
+			return "Error: " + err.Error()
+		}
+		magenta.Printf("\n🔧 %s: %s\n", call.Name, command)
+	} else {
+		magenta.Printf("\n🔧 %s %s\n", call.Name, compactJSON(args))
+	}
+
+	// Commands go through the risk policy, whatever the allowlist says
+	approval := approvalAuto
+	var assessment RiskAssessment
+	if command != "" {
+		assessment = classifyCommand(command)
+		action := riskPolicy[assessment.Level]
+		printRiskAssessment(assessment, action, "   ")
+
+		switch action {
+		case RiskBlock:
+			red.Println("   🚫 Blocked by risk policy")
+			return "The command was blocked by the user's risk policy and did not run."
+		case RiskConfirm:
+			if !typedConfirmation(a.reader, assessment.Level) {
+				yellow.Println("   ⏭️  Not confirmed")
+				return "The user did not confirm this command; it did not run."
+			}
+			approval = approvalTyped
+		}
+	}
+
+	if approval == approvalAuto && !a.allowed[call.Name] {
+		if !a.ask(call.Name) {
+			yellow.Println("   ⏭️  Denied")
+			return "The user denied this tool call."
+		}
+		approval = approvalConfirmed
+	}
+
+	if command == "" {
+		out, err := tool.run(args)
+		if err != nil {
+			red.Printf("   ✗ %v\n", err)
+			return "Error: " + err.Error()
+		}
+		green.Printf("   ✓ %d bytes returned\n", len(out))
+		return out
+	}
+
+	result := runQuiet(command, agentCommandTimeout)
+	entry := aiAudit("agent", a.task)
+	entry.Approval = approval
+	entry.Risk = assessment.Level.String()
+	recordAudit(entry, result)
+
+	if result.Status == StepSucceeded {
+		green.Printf("   ✓ exit code 0 (%s)\n", formatDuration(result.Duration))
+	} else {
+		red.Printf("   ✗ exit code %d (%s)\n", result.ExitCode, formatDuration(result.Duration))
+	}
+
+	out := fmt.Sprintf("Exit code: %d\n", result.ExitCode)
+	if result.Err != nil && result.ExitCode < 0 {
+		out += fmt.Sprintf("Error: %v\n", result.Err)
+	}
+	return out + truncateOutput(result.Stdout, maxToolOutput)
+}
+
+// ask prompts for one tool call; "always" allowlists the tool for the session
+func (a *agent) ask(name string) bool {
+	fmt.Printf("❓ Allow? [y]es, [n]o or [a]lways allow %s: ", name)
+	response, _ := a.reader.ReadString('\n')
+
+	switch strings.TrimSpace(strings.ToLower(response)) {
+	case "y", "yes":
+		return true
+	case "a", "always":
+		a.allowed[name] = true
+		return true
+	}
+	return false
+}
+
+// compactJSON renders tool arguments on one line for display
+func compactJSON(raw json.RawMessage) string {
+	var b bytes.Buffer
+	if err := json.Compact(&b, raw); err != nil {
+		return string(raw)
+	}
+	return b.String()
+}
+
+// looksBinary reports whether data contains a NUL byte near its start
+func looksBinary(data []byte) bool {
+	if len(data) > 8000 {
+		data = data[:8000]
+	}
+	return bytes.IndexByte(data, 0) >= 0
+}
+
+func readFileTool(args json.RawMessage) (string, error) {
+	var a struct {
+		Path string `json:"path"`
+	}
+	if err := json.Unmarshal(args, &a); err != nil {
+		return "", err
+	}
+	if a.Path == "" {
+		return "", fmt.Errorf("path is required")
+	}
+
+	file, err := attachFile(a.Path, maxToolOutput)
+	if errors.Is(err, errBinaryFile) {
+		return fmt.Sprintf("%s is a binary file", a.Path), nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if file.Truncated {
+		return file.Content + "\n" + file.TruncationNote(), nil
+	}
+	return file.Content, nil
+}
+
+func listDirectoryTool(args json.RawMessage) (string, error) {
+	var a struct {
+		Path string `json:"path"`
+	}
+	if err := json.Unmarshal(args, &a); err != nil {
+		return "", err
+	}
+	if a.Path == "" {
+		a.Path = "."
+	}
+
+	entries, err := os.ReadDir(a.Path)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	for i, entry := range entries {
+		if i == maxListEntries {
+			fmt.Fprintf(&b, "[... %d more entries not shown ...]\n", len(entries)-maxListEntries)
+			break
+		}
+		info, err := entry.Info()
+		switch {
+		case err != nil:
+			fmt.Fprintf(&b, "?  %s\n", entry.Name())
+		case entry.IsDir():
+			fmt.Fprintf(&b, "d  %s/\n", entry.Name())
+		case info.Mode()&fs.ModeSymlink != 0:
+			target, _ := os.Readlink(filepath.Join(a.Path, entry.Name()))
+			fmt.Fprintf(&b, "l  %s -> %s\n", entry.Name(), target)
+		default:
+			fmt.Fprintf(&b, "f  %s (%d bytes)\n", entry.Name(), info.Size())
+		}
+	}
+	if len(entries) == 0 {
+		return "The directory is empty.", nil
+	}
+	return b.String(), nil
+}
+
+func grepTool(args json.RawMessage) (string, error) {
+	var a struct {
+		Pattern    string `json:"pattern"`
+		Path       string `json:"path"`
+		IgnoreCase bool   `json:"ignore_case"`
+	}
+	if err := json.Unmarshal(args, &a); err != nil {
+		return "", err
+	}
+	if a.Path == "" {
+		a.Path = "."
+	}
+	if a.IgnoreCase {
+		a.Pattern = "(?i)" + a.Pattern
+	}
+
+	re, err := regexp.Compile(a.Pattern)
+	if err != nil {
+		return "", err
+	}
+
+	var matches []string
+	err = filepath.WalkDir(a.Path, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			// Hidden directories such as .git and dependency trees are noise
+			if path != a.Path && (strings.HasPrefix(d.Name(), ".") || d.Name() == "node_modules" || d.Name() == "vendor") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if info, err := d.Info(); err != nil || info.Size() > maxGrepFileSize {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil || looksBinary(data) {
+			return nil
+		}
+		for i, line := range strings.Split(string(data), "\n") {
+			if !re.MatchString(line) {
+				continue
+			}
+			if len(line) > 300 {
+				line = line[:300] + "..."
+			}
+			matches = append(matches, fmt.Sprintf("%s:%d: %s", path, i+1, line))
+			if len(matches) >= maxGrepMatches {
+				return fs.SkipAll
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if len(matches) == 0 {
+		return "No matches.", nil
+	}
+	sort.Strings(matches)
+	out := strings.Join(matches, "\n")
+	if len(matches) >= maxGrepMatches {
+		out += fmt.Sprintf("\n[... stopped after %d matches ...]", maxGrepMatches)
+	}
+	return truncateOutput(out, maxToolOutput), nil
+}
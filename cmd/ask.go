@@ -2,11 +2,11 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 
 	"github.com/fatih/color"
-	openai "github.com/sashabaranov/go-openai"
 	"github.com/spf13/cobra"
 )
 
@@ -14,64 +14,122 @@ var askCmd = &cobra.Command{
 	Use:   "ask [question]",
 	Short: "Ask a quick question to AI",
 	Long: `Ask a single question to the AI and get an immediate response.
+
+Piped input and files given with -f are sent along with the question. Each
+is limited to 100 KB, and to 200 KB together, with truncation marked; binary
+files are refused.
+
+With --output plain only the answer is printed, and --output json returns
+{question, answer, attachments, model, usage} for scripts.
 	
 Examples:
   livecli ask "How do I list all running processes?"
-  livecli ask "Explain what 'grep' command does"`,
+  livecli ask "Explain what 'grep' command does"
+  cat error.log | livecli ask "why is this failing?"
+  livecli ask -f main.go -f go.mod "review this"
+  livecli ask --output json "What is a zombie process?" | jq -r .answer`,
 	Args: cobra.MinimumNArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		question := strings.Join(args, " ")
-		askQuestion(question)
+		return askQuestion(question)
 	},
 }
 
+// askSystemPrompt is the system prompt for one-off questions
+const askSystemPrompt = "You are a helpful AI assistant specialized in programming, system administration, and command-line tools. Provide concise and accurate answers."
+
+var askFiles []string
+
+// oneShotSystemPrompt returns the system prompt for ask and explain: the one
+// from --system, LIVECLI_SYSTEM_PROMPT or the profile when set, and
+// askSystemPrompt otherwise
+func oneShotSystemPrompt() string {
+	if flag := rootCmd.PersistentFlags().Lookup("system"); flag != nil && systemPrompt != flag.DefValue {
+		return systemPrompt
+	}
+	return askSystemPrompt
+}
+
+// AskResult is the JSON output of ask
+type AskResult struct {
+	Question    string       `json:"question"`
+	Answer      string       `json:"answer"`
+	Attachments []Attachment `json:"attachments,omitempty"`
+	Model       string       `json:"model"`
+	Usage       Usage        `json:"usage"`
+}
+
 func init() {
 	rootCmd.AddCommand(askCmd)
+	askCmd.Flags().StringArrayVarP(&askFiles, "file", "f", nil, "Attach a file to the question (repeatable)")
 }
 
-func askQuestion(question string) {
-	if apiKey == "" {
-		color.Red("Error: OpenAI API key not set. Use --api-key flag or set OPENAI_API_KEY environment variable.")
-		return
+func askQuestion(question string) error {
+	provider, err := newProvider()
+	if err != nil {
+		return err
 	}
-	
-	cyan := color.New(color.FgCyan, color.Bold)
-	green := color.New(color.FgGreen, color.Bold)
-	
-	cyan.Printf("\n❓ Question: %s\n\n", question)
-	
-	ctx := context.Background()
-	client := openai.NewClient(apiKey)
-
-	resp, err := client.CreateChatCompletion(
-		ctx,
-		openai.ChatCompletionRequest{
-			Model: model,
-			Messages: []openai.ChatCompletionMessage{
-				{
-					Role:    openai.ChatMessageRoleSystem,
-					Content: "You are a helpful AI assistant specialized in programming, system administration, and command-line tools. Provide concise and accurate answers.",
-				},
-				{
-					Role:    openai.ChatMessageRoleUser,
-					Content: question,
-				},
+
+	attachments, err := collectAttachments(askFiles)
+	if err != nil {
+		return err
+	}
+
+	req := ChatRequest{
+		Model: model,
+		Messages: []Message{
+			{
+				Role:    RoleSystem,
+				Content: oneShotSystemPrompt(),
+			},
+			{
+				Role:    RoleUser,
+				Content: withAttachments(question, attachments),
 			},
-			Temperature: float32(temperature),
-			MaxTokens:   maxTokens,
 		},
-	)
-	if err != nil {
-		color.Red("Error: %v\n", err)
-		return
+		Temperature: temperature,
+		MaxTokens:   maxTokens,
 	}
-	
-	if len(resp.Choices) == 0 {
-		color.Red("Error: No response from AI\n")
-		return
+
+	if jsonOutput() {
+		resp, err := provider.Chat(context.Background(), req)
+		if err != nil {
+			return err
+		}
+		writeJSON(AskResult{
+			Question:    question,
+			Answer:      resp.Content,
+			Attachments: attachments,
+			Model:       model,
+			Usage:       resp.Usage,
+		})
+		return nil
 	}
-	
-	green.Println("💡 Answer:")
-	fmt.Println(resp.Choices[0].Message.Content)
+
+	cyan := color.New(color.FgCyan, color.Bold)
+	green := color.New(color.FgGreen, color.Bold)
+
+	if decoratedOutput() {
+		cyan.Printf("\n❓ Question: %s\n", question)
+		if len(attachments) > 0 {
+			fmt.Printf("📎 Attached: %s\n", strings.Join(attachmentNames(attachments), ", "))
+		}
+		fmt.Println()
+		green.Println("💡 Answer:")
+	}
+
+	_, err = streamResponse(provider, req)
+	if errors.Is(err, errResponseCancelled) {
+		color.Yellow("\n⏹️  Response cancelled")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
 	fmt.Println()
+	if decoratedOutput() {
+		fmt.Println()
+	}
+	return nil
 }
@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// maxAttachmentSize bounds the content sent for one file or stdin
+	maxAttachmentSize = 100 * 1024
+	// maxAttachmentsSize bounds the content attached to a single message
+	maxAttachmentsSize = 200 * 1024
+)
+
+// errBinaryFile is returned for files that are not text
+var errBinaryFile = errors.New("binary files cannot be attached")
+
+// Attachment is a file, or piped input, sent to the model with a message
+type Attachment struct {
+	Name    string `json:"name"`
+	Content string `json:"-"`
+	// Size is the full size; Content holds only the first bytes when it is
+	// larger. It is 0 for truncated input of unknown size, such as a pipe.
+	Size      int  `json:"size,omitempty"`
+	Truncated bool `json:"truncated"`
+}
+
+// Summary describes the attachment for display, e.g. "main.go (2.1 KB)"
+func (a Attachment) Summary() string {
+	if a.Truncated && a.Size == 0 {
+		return fmt.Sprintf("%s (truncated to %s)", a.Name, formatBytes(len(a.Content)))
+	}
+	if a.Truncated {
+		return fmt.Sprintf("%s (%s, truncated to %s)", a.Name, formatBytes(a.Size), formatBytes(len(a.Content)))
+	}
+	return fmt.Sprintf("%s (%s)", a.Name, formatBytes(a.Size))
+}
+
+// TruncationNote marks where the content of a truncated attachment was cut
+func (a Attachment) TruncationNote() string {
+	if a.Size > len(a.Content) {
+		return fmt.Sprintf("[... truncated, %d more bytes not shown ...]", a.Size-len(a.Content))
+	}
+	return "[... truncated ...]"
+}
+
+// readAttachment reads up to limit bytes of text from r, and one more to tell
+// whether there is more; the rest is never read, so endless pipes are fine.
+// The size of truncated input is left unknown. Binary data is rejected with
+// errBinaryFile.
+func readAttachment(name string, r io.Reader, limit int) (Attachment, error) {
+	data, err := io.ReadAll(io.LimitReader(r, int64(limit)+1))
+	if err != nil {
+		return Attachment{}, err
+	}
+	if looksBinary(data) {
+		return Attachment{}, fmt.Errorf("%s: %w", name, errBinaryFile)
+	}
+
+	size, truncated := len(data), false
+	if size > limit {
+		size, truncated = 0, true
+		data = data[:limit]
+		// Don't split a multi-byte character
+		for i := 1; i < utf8.UTFMax && len(data) > 0; i++ {
+			if last, n := utf8.DecodeLastRune(data); last != utf8.RuneError || n > 1 {
+				break
+			}
+			data = data[:len(data)-1]
+		}
+	}
+	return Attachment{Name: name, Content: string(data), Size: size, Truncated: truncated}, nil
+}
+
+// attachFile reads a text file for attaching
+func attachFile(path string, limit int) (Attachment, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Attachment{}, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return Attachment{}, err
+	}
+	if info.IsDir() {
+		return Attachment{}, fmt.Errorf("%s is a directory", path)
+	}
+
+	a, err := readAttachment(path, f, limit)
+	if a.Truncated && info.Mode().IsRegular() {
+		a.Size = int(info.Size())
+	}
+	return a, err
+}
+
+// stdinPiped reports whether stdin is a pipe or a file rather than a terminal.
+// /dev/null and terminals are never read, so a missing pipe can't block.
+func stdinPiped() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeNamedPipe != 0 || info.Mode().IsRegular()
+}
+
+// collectAttachments reads piped stdin, when there is any, and the given files,
+// keeping their total within maxAttachmentsSize
+func collectAttachments(paths []string) ([]Attachment, error) {
+	var attachments []Attachment
+	remaining := maxAttachmentsSize
+
+	add := func(a Attachment) {
+		attachments = append(attachments, a)
+		remaining -= len(a.Content)
+	}
+
+	if stdinPiped() {
+		a, err := readAttachment("stdin", os.Stdin, maxAttachmentSize)
+		if err != nil {
+			return nil, err
+		}
+		if info, err := os.Stdin.Stat(); err == nil && a.Truncated && info.Mode().IsRegular() {
+			a.Size = int(info.Size())
+		}
+		if strings.TrimSpace(a.Content) != "" {
+			add(a)
+		}
+	}
+
+	for _, path := range paths {
+		if remaining <= 0 {
+			return nil, fmt.Errorf("cannot attach %s: the attachments exceed the %s limit per message", path, formatBytes(maxAttachmentsSize))
+		}
+		a, err := attachFile(path, min(maxAttachmentSize, remaining))
+		if err != nil {
+			return nil, err
+		}
+		add(a)
+	}
+	return attachments, nil
+}
+
+// withAttachments appends attachments to a message, each framed with its name
+// and marked when truncated
+func withAttachments(message string, attachments []Attachment) string {
+	if len(attachments) == 0 {
+		return message
+	}
+
+	var b strings.Builder
+	b.WriteString(message)
+	for _, a := range attachments {
+		fmt.Fprintf(&b, "\n\n--- %s ---\n", a.Name)
+		b.WriteString(strings.TrimRight(a.Content, "\n"))
+		if a.Truncated {
+			b.WriteString("\n" + a.TruncationNote())
+		}
+		fmt.Fprintf(&b, "\n--- end of %s ---", a.Name)
+	}
+	return b.String()
+}
+
+// attachmentNames lists attachments for display
+func attachmentNames(attachments []Attachment) []string {
+	names := make([]string, len(attachments))
+	for i, a := range attachments {
+		names[i] = a.Summary()
+	}
+	return names
+}
+
+// formatBytes renders a size for display, e.g. "512 bytes" or "2.1 KB"
+func formatBytes(n int) string {
+	switch {
+	case n < 1024:
+		return fmt.Sprintf("%d bytes", n)
+	case n < 1024*1024:
+		return fmt.Sprintf("%.1f KB", float64(n)/1024)
+	default:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1024*1024))
+	}
+}
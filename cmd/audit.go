@@ -0,0 +1,316 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// maxAuditOutput bounds the command output kept in each audit entry
+const maxAuditOutput = 2000
+
+// How a command was approved before it ran
+const (
+	approvalAuto      = "auto-confirmed"
+	approvalConfirmed = "confirmed"
+	approvalTyped     = "typed-confirmation"
+	approvalUser      = "user-typed"
+	approvalEdited    = "user-edited"
+)
+
+// AuditEntry is one command livecli executed
+type AuditEntry struct {
+	Time        time.Time `json:"time"`
+	Source      string    `json:"source"`
+	Command     string    `json:"command"`
+	Task        string    `json:"task,omitempty"`
+	AIGenerated bool      `json:"ai_generated"`
+	Provider    string    `json:"provider,omitempty"`
+	Model       string    `json:"model,omitempty"`
+	Approval    string    `json:"approval"`
+	Risk        string    `json:"risk,omitempty"`
+	ExitCode    int       `json:"exit_code"`
+	DurationMs  int64     `json:"duration_ms"`
+	Output      string    `json:"output,omitempty"`
+	User        string    `json:"user,omitempty"`
+	Host        string    `json:"host,omitempty"`
+	Dir         string    `json:"dir,omitempty"`
+}
+
+// aiAudit starts an entry for commands suggested by the current model
+func aiAudit(source, task string) AuditEntry {
+	return AuditEntry{
+		Source:      source,
+		Task:        task,
+		AIGenerated: true,
+		Provider:    providerName,
+		Model:       model,
+	}
+}
+
+// auditLogPath returns the audit log location, which LIVECLI_AUDIT_LOG overrides
+func auditLogPath() (string, error) {
+	if path := os.Getenv("LIVECLI_AUDIT_LOG"); path != "" {
+		return path, nil
+	}
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "audit.jsonl"), nil
+}
+
+// recordAudit completes entry with the outcome of a command and appends it to
+// the audit log. Failures are reported but never stop the command flow.
+func recordAudit(entry AuditEntry, result StepResult) {
+	entry.Time = time.Now().UTC()
+	entry.Command = result.Command
+	entry.ExitCode = result.ExitCode
+	entry.DurationMs = result.Duration.Milliseconds()
+
+	output := result.Stdout
+	if result.Stderr != "" {
+		output = strings.TrimRight(output, "\n") + "\n" + result.Stderr
+	}
+	entry.Output = truncateOutput(strings.TrimLeft(output, "\n"), maxAuditOutput)
+
+	if u, err := user.Current(); err == nil {
+		entry.User = u.Username
+	}
+	entry.Host, _ = os.Hostname()
+	entry.Dir, _ = os.Getwd()
+
+	if err := appendAudit(entry); err != nil {
+		color.Yellow("⚠️  Could not write the audit log: %v", err)
+	}
+}
+
+func appendAudit(entry AuditEntry) error {
+	path, err := auditLogPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+// readAudit returns every entry in the audit log, oldest first
+func readAudit() ([]AuditEntry, error) {
+	path, err := auditLogPath()
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []AuditEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+var (
+	historyLimit  int
+	historySource string
+	historySince  time.Duration
+	historyFailed bool
+	historyAI     bool
+	historyGrep   string
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show the audit log of commands livecli executed",
+	Long: `Every command livecli executes is appended to an audit log (JSON lines):
+setup steps and checks, rollbacks, "do" and "fix" commands, chat agent
+commands, git operations and commands run in interactive mode. Each entry records the time, command,
+originating subcommand, task, model, approval mode, exit code, duration and
+output.
+
+The log lives in your config directory (audit.jsonl) unless
+LIVECLI_AUDIT_LOG points elsewhere.
+
+Examples:
+  livecli history
+  livecli history --ai --failed
+  livecli history --source setup --since 24h
+  livecli history show 42`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		entries, err := readAudit()
+		if err != nil {
+			return err
+		}
+
+		var shown []int
+		for i, entry := range entries {
+			if matchesHistoryFilters(entry) {
+				shown = append(shown, i)
+			}
+		}
+		if historyLimit > 0 && len(shown) > historyLimit {
+			shown = shown[len(shown)-historyLimit:]
+		}
+
+		if len(shown) == 0 {
+			fmt.Println("No matching commands in the audit log.")
+			return nil
+		}
+
+		for _, i := range shown {
+			printHistoryLine(i+1, entries[i])
+		}
+		return nil
+	},
+}
+
+var historyShowCmd = &cobra.Command{
+	Use:   "show <number>",
+	Short: "Show an audit log entry in full",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		n, err := strconv.Atoi(strings.TrimPrefix(args[0], "#"))
+		if err != nil {
+			return fmt.Errorf("invalid entry number %q", args[0])
+		}
+
+		entries, err := readAudit()
+		if err != nil {
+			return err
+		}
+		if n < 1 || n > len(entries) {
+			return fmt.Errorf("entry #%d not found (the log has %d entries)", n, len(entries))
+		}
+
+		printHistoryEntry(n, entries[n-1])
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+	historyCmd.AddCommand(historyShowCmd)
+
+	historyCmd.Flags().IntVarP(&historyLimit, "limit", "n", 20, "Show at most this many entries (0 = all)")
+	historyCmd.Flags().StringVar(&historySource, "source", "", "Only entries from this subcommand (setup, rollback, git, interactive, ...)")
+	historyCmd.Flags().DurationVar(&historySince, "since", 0, "Only entries newer than this, e.g. 24h or 30m")
+	historyCmd.Flags().BoolVar(&historyFailed, "failed", false, "Only commands that exited non-zero")
+	historyCmd.Flags().BoolVar(&historyAI, "ai", false, "Only AI-generated commands")
+	historyCmd.Flags().StringVar(&historyGrep, "grep", "", "Only entries whose command or task contains this text")
+}
+
+func matchesHistoryFilters(entry AuditEntry) bool {
+	if historySource != "" && !strings.HasPrefix(entry.Source, historySource) {
+		return false
+	}
+	if historySince > 0 && entry.Time.Before(time.Now().Add(-historySince)) {
+		return false
+	}
+	if historyFailed && entry.ExitCode == 0 {
+		return false
+	}
+	if historyAI && !entry.AIGenerated {
+		return false
+	}
+	if historyGrep != "" {
+		needle := strings.ToLower(historyGrep)
+		if !strings.Contains(strings.ToLower(entry.Command), needle) &&
+			!strings.Contains(strings.ToLower(entry.Task), needle) {
+			return false
+		}
+	}
+	return true
+}
+
+// printHistoryLine prints an entry as a single summary line
+func printHistoryLine(n int, entry AuditEntry) {
+	status := color.GreenString("%-5s", "✓")
+	if entry.ExitCode != 0 {
+		status = color.RedString("%-5s", fmt.Sprintf("✗ %d", entry.ExitCode))
+	}
+
+	ai := ""
+	if entry.AIGenerated {
+		ai = color.MagentaString("[AI] ")
+	}
+
+	fmt.Printf("%s  %s  %-12s %s  %s%s\n",
+		color.YellowString("#%-4d", n),
+		entry.Time.Local().Format("2006-01-02 15:04"),
+		entry.Source,
+		status,
+		ai,
+		entry.Command,
+	)
+}
+
+// printHistoryEntry prints every recorded field of an entry
+func printHistoryEntry(n int, entry AuditEntry) {
+	cyan := color.New(color.FgCyan, color.Bold)
+
+	cyan.Printf("\n📜 Audit entry #%d\n", n)
+	fmt.Printf("  Time:      %s\n", entry.Time.Local().Format(time.RFC1123))
+	fmt.Printf("  Source:    %s\n", entry.Source)
+	if entry.Task != "" {
+		fmt.Printf("  Task:      %s\n", entry.Task)
+	}
+	color.Magenta("  Command:   %s", entry.Command)
+	if entry.AIGenerated {
+		fmt.Printf("  Generated: by AI (%s, %s)\n", entry.Provider, entry.Model)
+	} else {
+		fmt.Printf("  Generated: by the user\n")
+	}
+	fmt.Printf("  Approval:  %s\n", entry.Approval)
+	if entry.Risk != "" {
+		fmt.Printf("  Risk:      %s\n", entry.Risk)
+	}
+	fmt.Printf("  Exit code: %d\n", entry.ExitCode)
+	fmt.Printf("  Duration:  %s\n", formatDuration(time.Duration(entry.DurationMs)*time.Millisecond))
+	fmt.Printf("  Where:     %s@%s in %s\n", entry.User, entry.Host, entry.Dir)
+
+	if entry.Output != "" {
+		cyan.Println("\nOutput:")
+		fmt.Println(entry.Output)
+	}
+	fmt.Println()
+}
@@ -1,150 +1,158 @@
 package cmd
 
 import (
-	"context"
+	"errors"
 	"fmt"
 	"strings"
 
-	"github.com/chzyer/readline"
 	"github.com/fatih/color"
-	openai "github.com/sashabaranov/go-openai"
 	"github.com/spf13/cobra"
 )
 
 var (
-	systemPrompt string
-	maxTokens    int
-	temperature  float64
+	systemPrompt  string
+	maxTokens     int
+	temperature   float64
+	resumeSession string
+	agentMode     bool
+	allowTools    []string
 )
 
 var chatCmd = &cobra.Command{
 	Use:   "chat",
 	Short: "Start an interactive AI chat session",
 	Long: `Start an interactive chat session with AI assistant.
-	
-Type your messages and get AI responses. Type 'exit' or 'quit' to end the session.
-Use '/clear' to clear conversation history.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		startChatSession()
+
+Type your messages and get AI responses. Type '/exit' or '/quit' to end the session
+and '/help' to list every command.
+
+Commands:
+  /clear               - Clear conversation history
+  /model [name]        - Show or switch the AI model
+  /system [prompt]     - Show or replace the system prompt
+  /temperature [value] - Show or set the response temperature
+  /history             - Show the conversation so far
+  /undo                - Remove the last exchange
+  /retry               - Regenerate the last AI response
+  /tokens              - Show token usage of the history
+  /save [name]         - Save the conversation (saved sessions are kept up to date)
+  /load <name>         - Load a saved session by ID or name
+  /attach [path]       - Attach a file to your next message
+
+Resume the most recent session with 'livecli chat --resume', or a specific one
+with 'livecli chat --resume <id|name>'.
+
+With --agent the AI can use tools to read files, list directories, search
+with grep, check git status and run shell commands. Every tool call is shown
+and needs your approval unless the tool is in --allow-tools; commands also go
+through the risk policy and are recorded in the audit log.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Allow "--resume <id>" in addition to "--resume=<id>"
+		if resumeSession == "latest" && len(args) == 1 {
+			resumeSession = args[0]
+		}
+		return startChatSession()
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(chatCmd)
-	
-	chatCmd.Flags().StringVarP(&systemPrompt, "system", "s", "You are a helpful AI assistant specialized in programming, system administration, and command-line tools.", "System prompt for the AI")
-	chatCmd.Flags().IntVarP(&maxTokens, "max-tokens", "t", 1000, "Maximum tokens in response")
-	chatCmd.Flags().Float64VarP(&temperature, "temperature", "T", 0.7, "Temperature for AI responses (0.0-2.0)")
+
+	chatCmd.Flags().
+		StringVarP(&resumeSession, "resume", "r", "", "Resume a saved session (most recent if no ID is given)")
+	chatCmd.Flags().Lookup("resume").NoOptDefVal = "latest"
+	addContextFlags(chatCmd)
+	chatCmd.Flags().BoolVar(&agentMode, "agent", false, "Let the AI call tools that read files and run commands, with your approval")
+	chatCmd.Flags().StringSliceVar(&allowTools, "allow-tools", nil,
+		"Tools to run without asking in agent mode ("+strings.Join(agentToolNames(), ", ")+")")
+	chatCmd.Flags().StringVar(&riskSpec, "risk-policy", "", "Override the action per risk level for agent commands, e.g. high=block,medium=confirm")
 }
 
-func startChatSession() {
-	if apiKey == "" {
-		color.Red("Error: OpenAI API key not set. Use --api-key flag or set OPENAI_API_KEY environment variable.")
-		return
+func startChatSession() error {
+	provider, err := newProvider()
+	if err != nil {
+		return err
 	}
-	
-	ctx := context.Background()
-	client := openai.NewClient(apiKey)
-
-	// Maintain conversation history
-	messages := []openai.ChatCompletionMessage{
-		{
-			Role:    openai.ChatMessageRoleSystem,
-			Content: systemPrompt,
-		},
+
+	if err := validateContextStrategy(); err != nil {
+		return err
 	}
-	
-	cyan := color.New(color.FgCyan, color.Bold)
-	green := color.New(color.FgGreen, color.Bold)
-	yellow := color.New(color.FgYellow)
-	
-	cyan.Println("\n╔═══════════════════════════════════════════════════════════╗")
-	cyan.Println("║           💬 AI Chat Session Started                      ║")
-	cyan.Println("╚═══════════════════════════════════════════════════════════╝")
-	yellow.Println("\nCommands: /clear (clear history), /exit or Ctrl+C (quit)")
-	fmt.Printf("Model: %s\n\n", model)
-	
-	// Setup readline for better input handling
-	rl, err := readline.New("You> ")
-	if err != nil {
-		color.Red("Error initializing readline: %v", err)
-		return
+
+	if err := validateSetupFlags(); err != nil {
+		return err
 	}
-	defer rl.Close()
-	
-	for {
-		line, err := rl.Readline()
-		if err != nil {
-			break
-		}
-		
-		userInput := strings.TrimSpace(line)
-		
-		if userInput == "" {
-			continue
+
+	var a *agent
+	if agentMode {
+		if !provider.SupportsTools() {
+			return fmt.Errorf("agent mode needs tool calling: %w", errToolsUnsupported)
 		}
-		
-		// Handle special commands
-		if userInput == "/exit" || userInput == "/quit" {
-			green.Println("\n👋 Goodbye!")
-			break
+		if a, err = newAgent(allowTools); err != nil {
+			return err
 		}
-		
-		if userInput == "/clear" {
-			messages = []openai.ChatCompletionMessage{
-				{
-					Role:    openai.ChatMessageRoleSystem,
-					Content: systemPrompt,
-				},
-			}
-			green.Println("✓ Conversation history cleared")
-			continue
+	}
+
+	r := newREPL(provider, "You> ")
+	r.agent = a
+	r.addAgentPrompt()
+
+	if resumeSession != "" {
+		ref := resumeSession
+		if ref == "latest" {
+			ref = ""
 		}
-		
-		// Add user message to history
-		messages = append(messages, openai.ChatCompletionMessage{
-			Role:    openai.ChatMessageRoleUser,
-			Content: userInput,
-		})
-		
-		// Get AI response
-		fmt.Print("\nAI> ")
-		response, err := getOpenAIResponse(ctx, client, messages)
+
+		loaded, err := findSession(ref)
 		if err != nil {
-			color.Red("Error: %v\n", err)
-			// Remove the last user message if there was an error
-			messages = messages[:len(messages)-1]
-			continue
+			return err
 		}
-		
-		// Add assistant response to history
-		messages = append(messages, openai.ChatCompletionMessage{
-			Role:    openai.ChatMessageRoleAssistant,
-			Content: response,
-		})
-		
-		fmt.Println(response)
-		fmt.Println()
+		r.loadSession(loaded)
 	}
+
+	cyan := color.New(color.FgCyan, color.Bold)
+	green := color.New(color.FgGreen, color.Bold)
+	yellow := color.New(color.FgYellow)
+
+	printBanner(cyan, cyan, "║           💬 AI Chat Session Started                      ║")
+	yellow.Println("\nCommands: /help (all commands), /clear, /save [name], /exit or Ctrl+C (quit)")
+	yellow.Println("Press Ctrl+C while the AI is answering to cancel the response")
+	fmt.Printf("Provider: %s  Model: %s\n", provider.Name(), model)
+	if a != nil {
+		allowed := "none"
+		if len(allowTools) > 0 {
+			allowed = strings.Join(allowTools, ", ")
+		}
+		fmt.Printf("🔧 Agent mode: tools %s (auto-approved: %s)\n", strings.Join(agentToolNames(), ", "), allowed)
+	}
+	fmt.Println()
+
+	if r.saved {
+		printSessionHistory(r.session)
+		green.Printf("✓ Resumed session %s\n\n", r.session.ID)
+	}
+
+	return r.run()
 }
 
-func getOpenAIResponse(ctx context.Context, client *openai.Client, messages []openai.ChatCompletionMessage) (string, error) {
-	resp, err := client.CreateChatCompletion(
-		ctx,
-		openai.ChatCompletionRequest{
+// getAIResponse streams the assistant's reply to stdout and returns the full response
+func getAIResponse(provider Provider, messages []Message, tools []Tool) (ChatResponse, error) {
+	resp, err := streamResponse(
+		provider,
+		ChatRequest{
 			Model:       model,
 			Messages:    messages,
-			Temperature: float32(temperature),
+			Temperature: temperature,
 			MaxTokens:   maxTokens,
+			Tools:       tools,
 		},
 	)
-	if err != nil {
-		return "", fmt.Errorf("chat error: %w", err)
+	if errors.Is(err, errResponseCancelled) {
+		return ChatResponse{}, err
 	}
-	
-	if len(resp.Choices) == 0 {
-		return "", fmt.Errorf("no response from AI")
+	if err != nil {
+		return ChatResponse{}, fmt.Errorf("chat error: %w", err)
 	}
-	
-	return resp.Choices[0].Message.Content, nil
+
+	return resp, nil
 }
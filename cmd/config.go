@@ -0,0 +1,481 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+const defaultProfileName = "default"
+
+// Profile is a named set of defaults stored in the config file
+type Profile struct {
+	Provider     string   `yaml:"provider,omitempty"`
+	BaseURL      string   `yaml:"base_url,omitempty"`
+	Model        string   `yaml:"model,omitempty"`
+	Temperature  *float64 `yaml:"temperature,omitempty"`
+	MaxTokens    *int     `yaml:"max_tokens,omitempty"`
+	SystemPrompt string   `yaml:"system_prompt,omitempty"`
+	AutoConfirm  *bool    `yaml:"auto_confirm,omitempty"`
+
+	ContextBudget   *int   `yaml:"context_budget,omitempty"`
+	ContextStrategy string `yaml:"context_strategy,omitempty"`
+
+	RiskPolicy string `yaml:"risk_policy,omitempty"`
+}
+
+// Config is the on-disk configuration file
+type Config struct {
+	CurrentProfile string              `yaml:"current_profile,omitempty"`
+	Profiles       map[string]*Profile `yaml:"profiles,omitempty"`
+}
+
+// configSetting maps a config key to the flag it provides a default for
+type configSetting struct {
+	key  string
+	flag string
+	env  string
+	get  func(p *Profile) string
+	set  func(p *Profile, value string) error
+}
+
+var configSettings = []configSetting{
+	{
+		key:  "provider",
+		flag: "provider",
+		env:  "LIVECLI_PROVIDER",
+		get:  func(p *Profile) string { return p.Provider },
+		set: func(p *Profile, v string) error {
+			if _, err := lookupProvider(v); err != nil {
+				return err
+			}
+			p.Provider = strings.ToLower(v)
+			return nil
+		},
+	},
+	{
+		key:  "base_url",
+		flag: "base-url",
+		env:  "LIVECLI_BASE_URL",
+		get:  func(p *Profile) string { return p.BaseURL },
+		set:  func(p *Profile, v string) error { p.BaseURL = v; return nil },
+	},
+	{
+		key:  "model",
+		flag: "model",
+		env:  "LIVECLI_MODEL",
+		get:  func(p *Profile) string { return p.Model },
+		set:  func(p *Profile, v string) error { p.Model = v; return nil },
+	},
+	{
+		key:  "temperature",
+		flag: "temperature",
+		env:  "LIVECLI_TEMPERATURE",
+		get: func(p *Profile) string {
+			if p.Temperature == nil {
+				return ""
+			}
+			return strconv.FormatFloat(*p.Temperature, 'f', -1, 64)
+		},
+		set: func(p *Profile, v string) error {
+			t, err := strconv.ParseFloat(v, 64)
+			if err != nil || t < 0 || t > 2 {
+				return fmt.Errorf("temperature must be a number between 0.0 and 2.0")
+			}
+			p.Temperature = &t
+			return nil
+		},
+	},
+	{
+		key:  "max_tokens",
+		flag: "max-tokens",
+		env:  "LIVECLI_MAX_TOKENS",
+		get: func(p *Profile) string {
+			if p.MaxTokens == nil {
+				return ""
+			}
+			return strconv.Itoa(*p.MaxTokens)
+		},
+		set: func(p *Profile, v string) error {
+			n, err := strconv.Atoi(v)
+			if err != nil || n <= 0 {
+				return fmt.Errorf("max_tokens must be a positive integer")
+			}
+			p.MaxTokens = &n
+			return nil
+		},
+	},
+	{
+		key:  "system_prompt",
+		flag: "system",
+		env:  "LIVECLI_SYSTEM_PROMPT",
+		get:  func(p *Profile) string { return p.SystemPrompt },
+		set:  func(p *Profile, v string) error { p.SystemPrompt = v; return nil },
+	},
+	{
+		key:  "auto_confirm",
+		flag: "yes",
+		env:  "LIVECLI_AUTO_CONFIRM",
+		get: func(p *Profile) string {
+			if p.AutoConfirm == nil {
+				return ""
+			}
+			return strconv.FormatBool(*p.AutoConfirm)
+		},
+		set: func(p *Profile, v string) error {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return fmt.Errorf("auto_confirm must be true or false")
+			}
+			p.AutoConfirm = &b
+			return nil
+		},
+	},
+	{
+		key:  "context_budget",
+		flag: "context-budget",
+		env:  "LIVECLI_CONTEXT_BUDGET",
+		get: func(p *Profile) string {
+			if p.ContextBudget == nil {
+				return ""
+			}
+			return strconv.Itoa(*p.ContextBudget)
+		},
+		set: func(p *Profile, v string) error {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				return fmt.Errorf("context_budget must be a non-negative integer")
+			}
+			p.ContextBudget = &n
+			return nil
+		},
+	},
+	{
+		key:  "context_strategy",
+		flag: "context-strategy",
+		env:  "LIVECLI_CONTEXT_STRATEGY",
+		get:  func(p *Profile) string { return p.ContextStrategy },
+		set: func(p *Profile, v string) error {
+			if v != "truncate" && v != "summarize" {
+				return fmt.Errorf("context_strategy must be truncate or summarize")
+			}
+			p.ContextStrategy = v
+			return nil
+		},
+	},
+	{
+		key:  "risk_policy",
+		flag: "risk-policy",
+		env:  "LIVECLI_RISK_POLICY",
+		get:  func(p *Profile) string { return p.RiskPolicy },
+		set: func(p *Profile, v string) error {
+			if _, err := parseRiskPolicy(v); err != nil {
+				return err
+			}
+			p.RiskPolicy = v
+			return nil
+		},
+	},
+}
+
+var profileName string
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage the configuration file and profiles",
+	Long: `Manage persistent settings stored in the LiveCLI config file.
+
+Settings are grouped into named profiles. Values are resolved with the
+precedence: command-line flags > environment variables > profile > defaults.
+
+Keys: provider, base_url, model, temperature, max_tokens, system_prompt, auto_confirm,
+      context_budget, context_strategy, risk_policy
+
+Examples:
+  livecli config set model gpt-4o
+  livecli config set --profile local provider ollama
+  livecli config use-profile local
+  livecli config list`,
+	// Config commands edit the file directly, so they must not fail on the
+	// profile resolution that every other command performs
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return nil
+	},
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print a setting from the active profile",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		setting, err := lookupSetting(args[0])
+		if err != nil {
+			return err
+		}
+
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+
+		profile := cfg.Profiles[activeProfileName(cfg)]
+		if profile != nil {
+			fmt.Println(setting.get(profile))
+		}
+		return nil
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Store a setting in the active profile",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		setting, err := lookupSetting(args[0])
+		if err != nil {
+			return err
+		}
+
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+
+		name := activeProfileName(cfg)
+		profile := cfg.profile(name)
+		if err := setting.set(profile, args[1]); err != nil {
+			return err
+		}
+
+		if err := saveConfig(cfg); err != nil {
+			return err
+		}
+		fmt.Printf("✓ %s.%s = %s\n", name, setting.key, setting.get(profile))
+		return nil
+	},
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all profiles and their settings",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+
+		path, _ := configPath()
+		fmt.Printf("Config file: %s\n", path)
+
+		if len(cfg.Profiles) == 0 {
+			fmt.Println("\nNo profiles defined. Create one with 'livecli config set <key> <value>'.")
+			return nil
+		}
+
+		active := activeProfileName(cfg)
+		names := make([]string, 0, len(cfg.Profiles))
+		for name := range cfg.Profiles {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			marker := " "
+			if name == active {
+				marker = "*"
+			}
+			fmt.Printf("\n%s %s\n", marker, name)
+
+			for _, setting := range configSettings {
+				if value := setting.get(cfg.Profiles[name]); value != "" {
+					fmt.Printf("    %-14s %s\n", setting.key, value)
+				}
+			}
+		}
+		fmt.Println()
+		return nil
+	},
+}
+
+var configUseProfileCmd = &cobra.Command{
+	Use:   "use-profile <name>",
+	Short: "Make a profile the default for future commands",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+
+		name := args[0]
+		if _, ok := cfg.Profiles[name]; !ok {
+			cfg.profile(name)
+			fmt.Printf("Created empty profile %q\n", name)
+		}
+		cfg.CurrentProfile = name
+
+		if err := saveConfig(cfg); err != nil {
+			return err
+		}
+		fmt.Printf("✓ Now using profile %q\n", name)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configGetCmd, configSetCmd, configListCmd, configUseProfileCmd)
+}
+
+// configPath returns the config file location, honouring LIVECLI_CONFIG
+func configPath() (string, error) {
+	if path := os.Getenv("LIVECLI_CONFIG"); path != "" {
+		return path, nil
+	}
+
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.yaml"), nil
+}
+
+// configDir returns the directory holding livecli's config and state files
+func configDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot locate config directory: %w", err)
+	}
+	return filepath.Join(dir, "livecli"), nil
+}
+
+// loadConfig reads the config file; a missing file yields an empty config
+func loadConfig() (*Config, error) {
+	cfg := &Config{Profiles: map[string]*Profile{}}
+
+	path, err := configPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if cfg.Profiles == nil {
+		cfg.Profiles = map[string]*Profile{}
+	}
+	return cfg, nil
+}
+
+// saveConfig writes the config file, creating its directory if needed
+func saveConfig(cfg *Config) error {
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// profile returns the named profile, creating it if it does not exist
+func (c *Config) profile(name string) *Profile {
+	if p, ok := c.Profiles[name]; ok && p != nil {
+		return p
+	}
+	p := &Profile{}
+	c.Profiles[name] = p
+	return p
+}
+
+// activeProfileName resolves --profile > LIVECLI_PROFILE > current_profile > default
+func activeProfileName(cfg *Config) string {
+	if profileName != "" {
+		return profileName
+	}
+	if env := os.Getenv("LIVECLI_PROFILE"); env != "" {
+		return env
+	}
+	if cfg.CurrentProfile != "" {
+		return cfg.CurrentProfile
+	}
+	return defaultProfileName
+}
+
+func lookupSetting(key string) (configSetting, error) {
+	for _, setting := range configSettings {
+		if setting.key == key {
+			return setting, nil
+		}
+	}
+
+	keys := make([]string, len(configSettings))
+	for i, setting := range configSettings {
+		keys[i] = setting.key
+	}
+	return configSetting{}, fmt.Errorf("unknown config key %q (available: %s)", key, strings.Join(keys, ", "))
+}
+
+// applyConfig fills in every flag the user did not pass from the environment,
+// then from the active profile, leaving built-in defaults as the last resort
+func applyConfig(cmd *cobra.Command) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	name := activeProfileName(cfg)
+	profile, ok := cfg.Profiles[name]
+	if !ok && (profileName != "" || os.Getenv("LIVECLI_PROFILE") != "") {
+		return fmt.Errorf("profile %q not found in config", name)
+	}
+	if profile == nil {
+		profile = &Profile{}
+	}
+
+	for _, setting := range configSettings {
+		flag := cmd.Flags().Lookup(setting.flag)
+		if flag == nil || flag.Changed {
+			continue
+		}
+
+		value := os.Getenv(setting.env)
+		source := setting.env
+		if value == "" {
+			value = setting.get(profile)
+			source = fmt.Sprintf("profile %q", name)
+		}
+		if value == "" {
+			continue
+		}
+
+		if err := flag.Value.Set(value); err != nil {
+			return fmt.Errorf("invalid %s from %s: %w", setting.key, source, err)
+		}
+	}
+	return nil
+}
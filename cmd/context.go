@@ -0,0 +1,296 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	contextBudget   int
+	contextStrategy string
+)
+
+// keepRecentMessages is how many of the latest messages summarization leaves untouched
+const keepRecentMessages = 4
+
+// modelWindow maps a model name prefix to its context window in tokens
+type modelWindow struct {
+	prefix string
+	tokens int
+}
+
+// modelWindows is checked in order, so more specific prefixes come first
+var modelWindows = []modelWindow{
+	{"gpt-4o", 128000},
+	{"gpt-4.1", 1000000},
+	{"gpt-4-turbo", 128000},
+	{"gpt-4-32k", 32768},
+	{"gpt-4", 8192},
+	{"gpt-3.5-turbo", 16385},
+	{"o1", 128000},
+	{"o3", 200000},
+	{"o4", 200000},
+	{"gemini", 1000000},
+	{"claude", 200000},
+	{"llama3", 128000},
+	{"llama2", 4096},
+	{"mistral", 32768},
+	{"qwen", 32768},
+}
+
+// defaultContextWindow is assumed for models we know nothing about
+const defaultContextWindow = 8192
+
+// addContextFlags registers the context management flags on a REPL command
+func addContextFlags(cmd *cobra.Command) {
+	cmd.Flags().IntVar(
+		&contextBudget,
+		"context-budget",
+		0,
+		"Maximum tokens of history sent to the model (0 = model window minus --max-tokens)",
+	)
+	cmd.Flags().StringVar(
+		&contextStrategy,
+		"context-strategy",
+		"truncate",
+		"How to shrink history over budget: truncate (drop oldest) or summarize",
+	)
+}
+
+// validateContextStrategy rejects unknown --context-strategy values
+func validateContextStrategy() error {
+	if contextStrategy != "truncate" && contextStrategy != "summarize" {
+		return fmt.Errorf("invalid --context-strategy %q (use truncate or summarize)", contextStrategy)
+	}
+	return nil
+}
+
+// contextWindow returns the context window for a model
+func contextWindow(modelName string) int {
+	name := strings.ToLower(modelName)
+	name = name[strings.LastIndex(name, "/")+1:]
+
+	for _, w := range modelWindows {
+		if strings.HasPrefix(name, w.prefix) {
+			return w.tokens
+		}
+	}
+	return defaultContextWindow
+}
+
+// charsPerToken approximates tokenizer density for a model family
+func charsPerToken(modelName string) float64 {
+	name := strings.ToLower(modelName)
+	switch {
+	case strings.Contains(name, "claude"):
+		return 3.5
+	case strings.Contains(name, "llama"), strings.Contains(name, "mistral"), strings.Contains(name, "qwen"):
+		return 3.2
+	default:
+		return 4
+	}
+}
+
+// countTokens estimates the number of tokens in text for a model
+func countTokens(modelName, text string) int {
+	if text == "" {
+		return 0
+	}
+	return int(float64(len(text))/charsPerToken(modelName)) + 1
+}
+
+// countMessageTokens estimates the prompt size of a conversation, including
+// the few tokens of framing each message costs
+func countMessageTokens(modelName string, messages []Message) int {
+	total := 3
+	for _, msg := range messages {
+		total += 4 + countTokens(modelName, msg.Content)
+		for _, call := range msg.ToolCalls {
+			total += 4 + countTokens(modelName, call.Name+call.Arguments)
+		}
+	}
+	return total
+}
+
+// historyBudget returns how many tokens of history may be sent to the model
+func historyBudget() int {
+	if contextBudget > 0 {
+		return contextBudget
+	}
+
+	budget := contextWindow(model) - maxTokens
+	if budget < 1024 {
+		budget = 1024
+	}
+	return budget
+}
+
+// pinnedCount returns how many leading messages are never removed: just the
+// system prompt. A summary may itself be summarized again or dropped.
+func pinnedCount(messages []Message) int {
+	if len(messages) > 0 && messages[0].Role == RoleSystem {
+		return 1
+	}
+	return 0
+}
+
+// fitContext returns the history to send with the next request, shrunk to the
+// budget using the configured strategy. The system prompt and the latest
+// message are always kept. r.messages itself is never shortened, so saved
+// sessions keep the whole conversation.
+func (r *repl) fitContext() []Message {
+	budget := historyBudget()
+	messages := r.contextMessages()
+	if countMessageTokens(model, messages) <= budget {
+		return messages
+	}
+
+	if contextStrategy == "summarize" && !r.summarizeFailed && r.summarizeHistory() {
+		messages = r.contextMessages()
+		if countMessageTokens(model, messages) <= budget {
+			return messages
+		}
+	}
+
+	return truncateHistory(messages, budget)
+}
+
+// contextMessages returns the history with the summarized messages, if any,
+// replaced by their summary
+func (r *repl) contextMessages() []Message {
+	// /undo and /retry may have removed summarized messages; the summary is
+	// then stale, since newer messages take their place
+	if r.summary != nil && r.summaryEnd >= len(r.messages) {
+		r.summary = nil
+	}
+	if r.summary == nil {
+		return r.messages
+	}
+
+	pinned := pinnedCount(r.messages)
+	messages := make([]Message, 0, pinned+1+len(r.messages)-r.summaryEnd)
+	messages = append(messages, r.messages[:pinned]...)
+	messages = append(messages, *r.summary)
+	return append(messages, r.messages[r.summaryEnd:]...)
+}
+
+// truncateHistory returns a copy of messages without the oldest unpinned
+// messages, so that it fits the budget
+func truncateHistory(messages []Message, budget int) []Message {
+	pinned := pinnedCount(messages)
+	trimmed := append([]Message(nil), messages...)
+
+	for countMessageTokens(model, trimmed) > budget && len(trimmed)-pinned > 1 {
+		trimmed = append(trimmed[:pinned], trimmed[pinned+1:]...)
+	}
+
+	// Never start the remaining conversation with an orphaned AI reply or tool result
+	for len(trimmed)-pinned > 1 && (trimmed[pinned].Role == RoleAssistant || trimmed[pinned].Role == RoleTool) {
+		trimmed = append(trimmed[:pinned], trimmed[pinned+1:]...)
+	}
+
+	if dropped := len(messages) - len(trimmed); dropped > 0 {
+		color.Yellow("✂️  Left out the %d oldest messages to stay within the %d token context budget", dropped, budget)
+	}
+	return trimmed
+}
+
+// summarizeHistory summarizes the older turns, together with any earlier
+// summary, for use in place of them in requests. It reports whether it
+// succeeded; after a failure it is not tried again in this session.
+func (r *repl) summarizeHistory() bool {
+	pinned := pinnedCount(r.messages)
+	start := pinned
+	if r.summary != nil {
+		start = r.summaryEnd
+	}
+	end := len(r.messages) - keepRecentMessages
+	// Tool results must stay with the AI message that called the tools
+	for end > start && r.messages[end].Role == RoleTool {
+		end--
+	}
+	if end-start < 2 {
+		return false
+	}
+
+	var transcript strings.Builder
+	if r.summary != nil {
+		fmt.Fprintf(&transcript, "%s\n\n", r.summary.Content)
+	}
+	for _, msg := range r.messages[start:end] {
+		fmt.Fprintf(&transcript, "%s: %s\n", msg.Role, msg.Content)
+		for _, call := range msg.ToolCalls {
+			fmt.Fprintf(&transcript, "(called %s %s)\n", call.Name, call.Arguments)
+		}
+		transcript.WriteString("\n")
+	}
+
+	color.Yellow("📝 Summarizing %d earlier messages to stay within the context budget...", end-pinned)
+
+	resp, err := r.provider.Chat(context.Background(), ChatRequest{
+		Model: model,
+		Messages: []Message{
+			{
+				Role: RoleSystem,
+				Content: "Summarize the following conversation between a user and an AI assistant. " +
+					"Keep facts, decisions, commands, file names and open questions. Be concise.",
+			},
+			{
+				Role:    RoleUser,
+				Content: transcript.String(),
+			},
+		},
+		Temperature: 0.2,
+		MaxTokens:   maxTokens,
+	})
+	if err != nil {
+		color.Red("Summarization failed, truncating instead for the rest of this session: %v", err)
+		r.summarizeFailed = true
+		return false
+	}
+
+	r.summary = &Message{
+		Role:    RoleSystem,
+		Content: "Summary of the earlier conversation:\n" + resp.Content,
+	}
+	r.summaryEnd = end
+	return true
+}
+
+// printTokenUsage shows how much of the context budget the history uses
+func (r *repl) printTokenUsage() {
+	cyan := color.New(color.FgCyan, color.Bold)
+
+	byRole := map[string]int{}
+	for _, msg := range r.messages {
+		byRole[msg.Role] += countTokens(model, msg.Content)
+		for _, call := range msg.ToolCalls {
+			byRole[msg.Role] += countTokens(model, call.Name+call.Arguments)
+		}
+	}
+
+	used := countMessageTokens(model, r.messages)
+	budget := historyBudget()
+
+	cyan.Println("\n📊 Token usage (estimated)")
+	fmt.Printf("  Model:     %s (context window %d)\n", model, contextWindow(model))
+	fmt.Printf("  History:   %d tokens across %d messages\n", used, len(r.messages))
+	fmt.Printf("    system     %d\n", byRole[RoleSystem])
+	fmt.Printf("    user       %d\n", byRole[RoleUser])
+	fmt.Printf("    assistant  %d\n", byRole[RoleAssistant])
+	if byRole[RoleTool] > 0 {
+		fmt.Printf("    tool       %d\n", byRole[RoleTool])
+	}
+	fmt.Printf("  Budget:    %d tokens (%d%% used, strategy: %s)\n", budget, used*100/budget, contextStrategy)
+	fmt.Printf("  Reserved:  %d tokens for the response\n", maxTokens)
+
+	if r.lastUsage.TotalTokens > 0 {
+		fmt.Printf("  Last call: %d prompt + %d completion tokens (reported by %s)\n",
+			r.lastUsage.PromptTokens, r.lastUsage.CompletionTokens, r.provider.Name())
+	}
+	fmt.Println()
+}
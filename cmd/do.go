@@ -0,0 +1,266 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/chzyer/readline"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	doAutoConfirm bool
+	doDryRun      bool
+)
+
+var doCmd = &cobra.Command{
+	Use:   "do <request>",
+	Short: "Turn a sentence into a shell command and run it",
+	Long: `Describe what you want done and the AI proposes a single shell command
+for this system, with an explanation. Nothing runs until you confirm it, and
+you can edit the command inline first. The risk policy of setup applies here
+too.
+
+Examples:
+  livecli do "find all files over 100MB modified this week"
+  livecli do "show which process is listening on port 8080"
+  livecli do --dry-run "compress the logs directory into a dated tarball"`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeDo(strings.Join(args, " "))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(doCmd)
+	doCmd.Flags().BoolVarP(&doAutoConfirm, "yes", "y", false, "Run the command without asking (the risk policy still applies)")
+	doCmd.Flags().BoolVar(&doDryRun, "dry-run", false, "Show the command without running it")
+	doCmd.Flags().StringVar(&riskSpec, "risk-policy", "", "Override the action per risk level, e.g. high=block,medium=confirm")
+}
+
+const doPrompt = `You turn a request into a single shell command for the user's system.
+
+System:
+%s- Working directory: %s
+
+Rules:
+1. Respond with ONE command that does exactly what was asked; chain with pipes or && if needed
+2. Only use tools available on this system, with the flags of their versions (e.g. GNU vs BSD)
+3. Prefer read-only commands; never delete or overwrite data unless the request asks for it
+4. Only use sudo if the request needs root
+5. The explanation says in one or two sentences what the command does
+
+Respond with ONLY this JSON object, no markdown or commentary:
+{"command": "find . -type f -size +100M -mtime -7", "explanation": "Lists files below the current directory larger than 100MB that were modified in the last 7 days."}`
+
+// CommandSuggestion is a command proposed for a natural-language request
+type CommandSuggestion struct {
+	Command     string `json:"command"`
+	Explanation string `json:"explanation"`
+}
+
+// DoReport is the JSON output of do
+type DoReport struct {
+	Request     string      `json:"request,omitempty"`
+	Command     string      `json:"command"`
+	Explanation string      `json:"explanation"`
+	Risk        string      `json:"risk"`
+	Edited      bool        `json:"edited"`
+	Result      *StepReport `json:"result"`
+}
+
+// generateCommand asks the model for a command fulfilling request
+func generateCommand(provider Provider, request string) (CommandSuggestion, error) {
+	dir, _ := os.Getwd()
+	messages := []Message{
+		{Role: RoleSystem, Content: fmt.Sprintf(doPrompt, probeSystem().PromptContext(), dir)},
+		{Role: RoleUser, Content: request},
+	}
+
+	var suggestion CommandSuggestion
+	err := requestJSON(provider, messages, "command", func(content string) (err error) {
+		suggestion, err = parseCommandSuggestion(content)
+		return err
+	})
+	return suggestion, err
+}
+
+// parseCommandSuggestion finds the command object in a model response
+func parseCommandSuggestion(content string) (CommandSuggestion, error) {
+	objects := jsonObjects(content)
+	if len(objects) == 0 {
+		return CommandSuggestion{}, fmt.Errorf("the response contains no JSON object")
+	}
+
+	for _, object := range objects {
+		var suggestion CommandSuggestion
+		if err := json.Unmarshal([]byte(object), &suggestion); err != nil {
+			return CommandSuggestion{}, fmt.Errorf("invalid command JSON: %w", err)
+		}
+		suggestion.Command = strings.TrimSpace(suggestion.Command)
+		suggestion.Explanation = strings.TrimSpace(suggestion.Explanation)
+		if suggestion.Command != "" {
+			return suggestion, nil
+		}
+	}
+	return CommandSuggestion{}, fmt.Errorf(`the JSON object has no "command"`)
+}
+
+func executeDo(request string) error {
+	if err := validateSetupFlags(); err != nil {
+		return err
+	}
+
+	provider, err := newProvider()
+	if err != nil {
+		return err
+	}
+
+	cyan := color.New(color.FgCyan, color.Bold)
+	yellow := color.New(color.FgYellow, color.Bold)
+
+	printBanner(cyan, cyan, "║           ⚡ AI Command                                    ║")
+	fmt.Printf("\n📋 Request: %s\n", request)
+	yellow.Println("\n⏳ Working out the command...")
+
+	suggestion, err := generateCommand(provider, request)
+	if err != nil {
+		return fmt.Errorf("generating the command: %w", err)
+	}
+
+	report := DoReport{Request: request}
+	if jsonOutput() {
+		defer writeJSON(&report)
+	}
+
+	return runSuggestion(suggestion, aiAudit("do", request), doAutoConfirm, doDryRun, &report)
+}
+
+// runSuggestion shows a suggested command, lets the user edit and confirm it
+// and runs it, subject to the risk policy. The outcome is recorded in report.
+// It returns an error when the command fails, or is blocked or not confirmed
+// while running unattended, so scripts see a non-zero exit; declining to run
+// it is not one.
+func runSuggestion(suggestion CommandSuggestion, audit AuditEntry, autoConfirm, dryRun bool, report *DoReport) error {
+	green := color.New(color.FgGreen, color.Bold)
+	yellow := color.New(color.FgYellow, color.Bold)
+	magenta := color.New(color.FgMagenta, color.Bold)
+	red := color.New(color.FgRed, color.Bold)
+
+	report.Explanation = suggestion.Explanation
+	if suggestion.Explanation != "" {
+		fmt.Printf("\n💡 %s\n", suggestion.Explanation)
+	}
+
+	command := suggestion.Command
+	reader := bufio.NewReader(os.Stdin)
+	var assessment RiskAssessment
+	var action RiskAction
+
+confirm:
+	for {
+		assessment = classifyCommand(command)
+		action = riskPolicy[assessment.Level]
+		report.Command, report.Risk = command, assessment.Level.String()
+
+		magenta.Printf("\n💻 Command: %s\n", command)
+		printRiskAssessment(assessment, action, "")
+
+		if dryRun {
+			green.Println("\n✓ Dry run complete. The command was not executed.")
+			return nil
+		}
+
+		blocked := action == RiskBlock
+		if blocked {
+			red.Println("🚫 Blocked by risk policy")
+		}
+		if autoConfirm {
+			if blocked {
+				return fmt.Errorf("the command is blocked by the risk policy (%s risk)", assessment.Level)
+			}
+			break
+		}
+
+		choices := "[y]es, [e]dit or [n]o"
+		if blocked {
+			choices = "[e]dit or [n]o"
+		}
+		fmt.Printf("\n❓ Run this command? %s: ", choices)
+		response, _ := reader.ReadString('\n')
+
+		switch strings.TrimSpace(strings.ToLower(response)) {
+		case "y", "yes":
+			if !blocked {
+				break confirm
+			}
+		case "e", "edit":
+			edited, err := editCommand(command)
+			if err != nil || edited == "" {
+				break
+			}
+			report.Edited = report.Edited || edited != command
+			command = edited
+			continue
+		}
+
+		yellow.Println("\n❌ Cancelled. Nothing was executed.")
+		return nil
+	}
+
+	approval := approvalAuto
+	switch {
+	case action == RiskConfirm:
+		if !typedConfirmation(reader, assessment.Level) {
+			yellow.Println("\n❌ Not confirmed. Nothing was executed.")
+			if autoConfirm {
+				return fmt.Errorf("the command needs typed confirmation (%s risk), which was not given", assessment.Level)
+			}
+			return nil
+		}
+		approval = approvalTyped
+	case report.Edited:
+		approval = approvalEdited
+	case !autoConfirm:
+		approval = approvalConfirmed
+	}
+
+	fmt.Println()
+	result := runCommand(command)
+
+	entry := audit
+	entry.Approval = approval
+	entry.Risk = assessment.Level.String()
+	recordAudit(entry, result)
+
+	stepReport := newStepReport("", result)
+	report.Result = &stepReport
+
+	if result.Status != StepSucceeded {
+		red.Printf("\n✗ exit code %d (%s)\n", result.ExitCode, formatDuration(result.Duration))
+		return fmt.Errorf("the command failed with exit code %d", result.ExitCode)
+	}
+	green.Printf("\n✓ exit code 0 (%s)\n", formatDuration(result.Duration))
+	return nil
+}
+
+// editCommand lets the user change a command in place. An empty result or an
+// error (e.g. Ctrl+C) means the edit was abandoned.
+func editCommand(command string) (string, error) {
+	rl, err := readline.NewEx(&readline.Config{
+		Prompt: "✏️  ",
+		Stdout: os.Stdout,
+		Stderr: os.Stderr,
+	})
+	if err != nil {
+		return "", fmt.Errorf("error initializing readline: %w", err)
+	}
+	defer rl.Close()
+
+	line, err := rl.ReadlineWithDefault(command)
+	return strings.TrimSpace(line), err
+}
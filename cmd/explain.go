@@ -0,0 +1,399 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// docsTimeout bounds how long man may take for one program
+	docsTimeout = 10 * time.Second
+	// maxProgramDocs bounds the documentation sent for one program
+	maxProgramDocs = 4000
+	// maxDocHeadLines is how much of a page's start, its name and synopsis, is kept
+	maxDocHeadLines = 15
+	// maxFlagLines bounds the lines kept for a single flag's entry
+	maxFlagLines = 15
+)
+
+var explainCmd = &cobra.Command{
+	Use:   "explain <command>",
+	Short: "Explain what a shell command and each of its flags does",
+	Long: `Break a command into its programs, flags and arguments and explain each
+part. The relevant sections of the local man pages are sent with the
+question, so the explanation matches the versions installed on this system.
+
+Examples:
+  livecli explain "tar -xzvf foo.tgz -C /opt"
+  livecli explain "find . -name '*.log' -mtime +30 -delete"
+  livecli explain "ps aux | grep nginx | awk '{print \$2}'"`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return explainCommand(strings.Join(args, " "))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(explainCmd)
+}
+
+// ExplainResult is the JSON output of explain
+type ExplainResult struct {
+	Command     string   `json:"command"`
+	Explanation string   `json:"explanation"`
+	Sources     []string `json:"sources"`
+	Model       string   `json:"model"`
+	Usage       Usage    `json:"usage"`
+}
+
+// commandPart is one program in a command line and the flags passed to it
+type commandPart struct {
+	program string
+	flags   []string
+}
+
+// programDocs is the local documentation found for a program
+type programDocs struct {
+	source string // e.g. "man tar"
+	text   string
+}
+
+func explainCommand(command string) error {
+	provider, err := newProvider()
+	if err != nil {
+		return err
+	}
+
+	cyan := color.New(color.FgCyan, color.Bold)
+	green := color.New(color.FgGreen, color.Bold)
+	yellow := color.New(color.FgYellow)
+
+	if decoratedOutput() {
+		cyan.Printf("\n🔍 Command: %s\n", command)
+	}
+
+	var docs []programDocs
+	audit := AuditEntry{Source: "explain", Task: command}
+	for _, part := range commandParts(command) {
+		if d, ok := lookupDocs(part.program, audit); ok {
+			d.text = docExcerpt(d.text, part.flags)
+			docs = append(docs, d)
+		}
+	}
+
+	var sources []string
+	for _, d := range docs {
+		sources = append(sources, d.source)
+	}
+	if decoratedOutput() {
+		if len(sources) > 0 {
+			fmt.Printf("📚 Grounded in: %s\n\n", strings.Join(sources, ", "))
+		} else {
+			yellow.Print("📚 No local documentation found, answering from the model's knowledge\n\n")
+		}
+	}
+
+	req := ChatRequest{
+		Model: model,
+		Messages: []Message{
+			{
+				Role: RoleSystem,
+				Content: oneShotSystemPrompt() + " When local documentation is provided, trust it over your memory: " +
+					"it matches the versions installed on this system.",
+			},
+			{Role: RoleUser, Content: explainPrompt(command, docs)},
+		},
+		Temperature: temperature,
+		MaxTokens:   maxTokens,
+	}
+
+	if jsonOutput() {
+		resp, err := provider.Chat(context.Background(), req)
+		if err != nil {
+			return err
+		}
+		writeJSON(ExplainResult{
+			Command:     command,
+			Explanation: resp.Content,
+			Sources:     sources,
+			Model:       model,
+			Usage:       resp.Usage,
+		})
+		return nil
+	}
+
+	if decoratedOutput() {
+		green.Println("💡 Explanation:")
+	}
+
+	_, err = streamResponse(provider, req)
+	if errors.Is(err, errResponseCancelled) {
+		color.Yellow("\n⏹️  Response cancelled")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	if decoratedOutput() {
+		fmt.Println()
+	}
+	return nil
+}
+
+// explainPrompt asks for a part-by-part explanation grounded in docs
+func explainPrompt(command string, docs []programDocs) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Explain this shell command:\n\n    %s\n\n", command)
+	b.WriteString("Break it into its parts and explain each program, flag and argument in order, " +
+		"one per line. Then say in one sentence what the whole command does, and point out " +
+		"anything destructive or surprising.\n")
+
+	if len(docs) == 0 {
+		b.WriteString("\nNo local documentation was found. Say so if you are unsure what a flag means.\n")
+		return b.String()
+	}
+
+	b.WriteString("\nDocumentation installed on this system (excerpts):\n")
+	for _, d := range docs {
+		fmt.Fprintf(&b, "\n### %s\n%s\n", d.source, d.text)
+	}
+	return b.String()
+}
+
+// commandWrappers run the program that follows them, so that program is
+// explained as well
+var commandWrappers = map[string]bool{
+	"sudo": true, "doas": true, "env": true, "nohup": true, "time": true,
+	"nice": true, "exec": true, "xargs": true, "watch": true, "timeout": true,
+}
+
+var assignmentPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*=`)
+
+// commandParts lists the programs in a command line with their flags. It
+// understands quoting, pipelines, command lists and wrappers such as sudo.
+func commandParts(command string) []commandPart {
+	var parts []commandPart
+	var current *commandPart
+	prev := ""
+
+	for _, word := range shellWords(command) {
+		switch {
+		case isShellOperator(word):
+			current = nil
+		case assignmentPattern.MatchString(word) && (current == nil || current.program == "env"):
+			// VAR=value before the program
+		case strings.HasPrefix(word, "-") && word != "-" && word != "--" && current != nil:
+			flag, _, _ := strings.Cut(word, "=")
+			current.flags = append(current.flags, flag)
+		case current == nil || (commandWrappers[current.program] && !isWrapperArgument(current.program, prev, word)):
+			parts = append(parts, commandPart{program: word})
+			current = &parts[len(parts)-1]
+		}
+		prev = word
+	}
+	return parts
+}
+
+var durationPattern = regexp.MustCompile(`^[0-9.]+[smhd]?$`)
+
+// isWrapperArgument reports whether word is an argument of the wrapper rather
+// than the program it runs, e.g. the duration in "timeout 5 cmd"
+func isWrapperArgument(wrapper, prev, word string) bool {
+	switch wrapper {
+	case "timeout", "watch":
+		if durationPattern.MatchString(word) {
+			return true
+		}
+	}
+	// The value of a flag such as "sudo -u admin" or "nice -n 10"
+	switch prev {
+	case "-u", "-g", "-n":
+		return true
+	}
+	return false
+}
+
+func isShellOperator(word string) bool {
+	switch word {
+	case "|", "||", "&&", ";", "&", "|&":
+		return true
+	}
+	return false
+}
+
+// shellWords splits a command line into words the way a POSIX shell would,
+// removing quotes and escapes. Pipes and command separators become words of
+// their own. It does not expand anything.
+func shellWords(command string) []string {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+
+	flush := func() {
+		if inWord {
+			words = append(words, word.String())
+			word.Reset()
+			inWord = false
+		}
+	}
+
+	runes := []rune(command)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else if r == '\\' && quote == '"' && i+1 < len(runes) && strings.ContainsRune(`"\$`+"`", runes[i+1]) {
+				i++
+				word.WriteRune(runes[i])
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == '\\' && i+1 < len(runes):
+			i++
+			word.WriteRune(runes[i])
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n':
+			flush()
+		case r == '|' || r == '&' || r == ';':
+			flush()
+			op := string(r)
+			if i+1 < len(runes) && (runes[i+1] == r || (r == '|' && runes[i+1] == '&')) && r != ';' {
+				i++
+				op += string(runes[i])
+			}
+			words = append(words, op)
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	flush()
+	return words
+}
+
+var (
+	programNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.+-]+$`)
+	overstrikePattern  = regexp.MustCompile(".\x08")
+)
+
+// lookupDocs returns the man page of program. The program itself is never
+// run, since even --help may have side effects. The man lookup is recorded in
+// the audit log based on the audit template.
+func lookupDocs(program string, audit AuditEntry) (programDocs, bool) {
+	name := filepath.Base(program)
+	if !programNamePattern.MatchString(name) || !onPath("man") {
+		return programDocs{}, false
+	}
+
+	result := runQuiet("MANPAGER=cat MANWIDTH=100 man "+name+" 2>/dev/null", docsTimeout)
+	audit.Approval = approvalAuto
+	audit.Risk = RiskLow.String()
+	recordAudit(audit, result)
+
+	text := overstrikePattern.ReplaceAllString(result.Stdout, "")
+	if result.Status != StepSucceeded || strings.TrimSpace(text) == "" {
+		return programDocs{}, false
+	}
+	return programDocs{source: "man " + name, text: text}, true
+}
+
+// docExcerpt keeps the start of a page, which names and summarizes the program,
+// up to the first option, plus the entry of every flag in flags. Combined short flags such as -xzvf
+// are looked up letter by letter when the page has no entry for them as a whole.
+func docExcerpt(doc string, flags []string) string {
+	lines := strings.Split(strings.ReplaceAll(doc, "\r", ""), "\n")
+
+	keep := make([]bool, len(lines))
+	kept := 0
+	for i := 0; i < len(lines) && kept < maxDocHeadLines; i++ {
+		if strings.HasPrefix(strings.TrimSpace(lines[i]), "-") {
+			break
+		}
+		keep[i] = true
+		if strings.TrimSpace(lines[i]) != "" {
+			kept++
+		}
+	}
+
+	for _, flag := range flags {
+		if markFlagEntries(lines, keep, flag) {
+			continue
+		}
+		if !strings.HasPrefix(flag, "--") && len(flag) > 2 {
+			for _, letter := range flag[1:] {
+				markFlagEntries(lines, keep, "-"+string(letter))
+			}
+		}
+	}
+
+	var b strings.Builder
+	gap := false
+	for i, line := range lines {
+		if !keep[i] {
+			gap = true
+			continue
+		}
+		if gap && b.Len() > 0 {
+			b.WriteString("   [...]\n")
+		}
+		gap = false
+		b.WriteString(strings.TrimRight(line, " \t") + "\n")
+	}
+
+	excerpt := b.String()
+	if len(excerpt) > maxProgramDocs {
+		excerpt = excerpt[:maxProgramDocs] + "\n   [... documentation truncated ...]\n"
+	}
+	return excerpt
+}
+
+// markFlagEntries marks the lines documenting flag: the line listing it and
+// the more deeply indented description below it, up to the next option. It reports whether any
+// entry was found.
+func markFlagEntries(lines []string, keep []bool, flag string) bool {
+	pattern := regexp.MustCompile(`^\s*(-[^\s,]+(\s*[ =]\s*\S+)?,\s*)*` + regexp.QuoteMeta(flag) + `($|[\s,=\[])`)
+
+	found := false
+	for i, line := range lines {
+		if !pattern.MatchString(line) {
+			continue
+		}
+		found = true
+		keep[i] = true
+
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+		blanks := 0
+		for j := i + 1; j < len(lines) && j <= i+maxFlagLines; j++ {
+			next := lines[j]
+			if strings.TrimSpace(next) == "" {
+				if blanks++; blanks > 1 {
+					break
+				}
+				continue
+			}
+			// The next option, which --help output often indents further
+			if len(next)-len(strings.TrimLeft(next, " \t")) <= indent || strings.HasPrefix(strings.TrimSpace(next), "-") {
+				break
+			}
+			blanks = 0
+			keep[j] = true
+		}
+	}
+	return found
+}
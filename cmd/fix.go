@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	fixAutoConfirm bool
+	fixDryRun      bool
+)
+
+// maxFixStderr bounds the error output sent to the model; the tail is kept
+const maxFixStderr = 4000
+
+var fixCmd = &cobra.Command{
+	Use:   "fix",
+	Short: "Propose a corrected version of the last failed shell command",
+	Long: `Send the last command you ran in your shell, its exit code and, when
+captured, its error output to the AI, and get a corrected command to run.
+Nothing runs until you confirm it, and you can edit the command first. The
+risk policy of setup applies here too.
+
+The command is recorded by the shell integration; set it up once with
+'livecli shell-init --help'.
+
+Examples:
+  git pshu origin main
+  livecli fix`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return executeFix()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(fixCmd)
+	fixCmd.Flags().BoolVarP(&fixAutoConfirm, "yes", "y", false, "Run the corrected command without asking (the risk policy still applies)")
+	fixCmd.Flags().BoolVar(&fixDryRun, "dry-run", false, "Show the corrected command without running it")
+	fixCmd.Flags().StringVar(&riskSpec, "risk-policy", "", "Override the action per risk level, e.g. high=block,medium=confirm")
+}
+
+const fixPrompt = `You fix shell commands that failed on the user's system.
+
+System:
+%s- Working directory: %s
+
+Rules:
+1. Respond with ONE command that does what the failed command was meant to do
+2. Fix the cause shown by the exit code and error output: typos, wrong flags for this system's tool versions, missing arguments, wrong paths
+3. If a tool is missing, respond with the command that installs it with this system's package manager
+4. Only add sudo when the error shows a permission problem that needs root
+5. The explanation says in one or two sentences what was wrong and what the new command changes
+
+Respond with ONLY this JSON object, no markdown or commentary:
+{"command": "git push origin main", "explanation": "\"pshu\" is not a git command; you meant push."}`
+
+// FailedCommand is the last command recorded by the shell integration
+type FailedCommand struct {
+	Command  string
+	ExitCode int
+	Stderr   string
+}
+
+// FixReport is the JSON output of fix
+type FixReport struct {
+	FailedCommand  string `json:"failed_command"`
+	FailedExitCode int    `json:"failed_exit_code"`
+	DoReport
+}
+
+var ansiPattern = regexp.MustCompile("\x1b\\[[0-9;?]*[A-Za-z]")
+
+// lastFailedCommand reads what the shell integration recorded
+func lastFailedCommand() (FailedCommand, error) {
+	command := strings.TrimSpace(os.Getenv("LIVECLI_LAST_COMMAND"))
+	if command == "" {
+		return FailedCommand{}, fmt.Errorf("no command recorded; set up the shell integration, e.g. add " +
+			`eval "$(livecli shell-init bash)" to ~/.bashrc`)
+	}
+
+	exitCode, err := strconv.Atoi(os.Getenv("LIVECLI_LAST_STATUS"))
+	if err != nil {
+		return FailedCommand{}, fmt.Errorf("invalid LIVECLI_LAST_STATUS %q", os.Getenv("LIVECLI_LAST_STATUS"))
+	}
+	if exitCode == 0 {
+		return FailedCommand{}, fmt.Errorf("the last command succeeded, nothing to fix: %s", command)
+	}
+
+	failed := FailedCommand{Command: command, ExitCode: exitCode}
+	if path := os.Getenv("LIVECLI_LAST_STDERR"); path != "" {
+		if data, err := os.ReadFile(path); err == nil {
+			failed.Stderr = truncateOutput(ansiPattern.ReplaceAllString(string(data), ""), maxFixStderr)
+		}
+	}
+	return failed, nil
+}
+
+// generateFix asks the model for a command that fixes a failed one
+func generateFix(provider Provider, failed FailedCommand) (CommandSuggestion, error) {
+	dir, _ := os.Getwd()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "This command failed with exit code %d:\n\n    %s\n", failed.ExitCode, failed.Command)
+	if strings.TrimSpace(failed.Stderr) != "" {
+		fmt.Fprintf(&b, "\nError output:\n%s\n", failed.Stderr)
+	} else {
+		b.WriteString("\nNo error output was captured.\n")
+	}
+
+	messages := []Message{
+		{Role: RoleSystem, Content: fmt.Sprintf(fixPrompt, probeSystem().PromptContext(), dir)},
+		{Role: RoleUser, Content: b.String()},
+	}
+
+	var suggestion CommandSuggestion
+	err := requestJSON(provider, messages, "command", func(content string) (err error) {
+		suggestion, err = parseCommandSuggestion(content)
+		return err
+	})
+	return suggestion, err
+}
+
+func executeFix() error {
+	if err := validateSetupFlags(); err != nil {
+		return err
+	}
+
+	failed, err := lastFailedCommand()
+	if err != nil {
+		return err
+	}
+
+	provider, err := newProvider()
+	if err != nil {
+		return err
+	}
+
+	cyan := color.New(color.FgCyan, color.Bold)
+	yellow := color.New(color.FgYellow, color.Bold)
+	red := color.New(color.FgRed, color.Bold)
+
+	printBanner(cyan, cyan, "║           🩹 Fix Last Command                             ║")
+	red.Printf("\n❌ Failed: %s (exit code %d)\n", failed.Command, failed.ExitCode)
+	if failed.Stderr != "" {
+		fmt.Println(indentLines(truncateOutput(failed.Stderr, maxSandboxOutput), "   "))
+	}
+	yellow.Println("\n⏳ Working out a fix...")
+
+	suggestion, err := generateFix(provider, failed)
+	if err != nil {
+		return fmt.Errorf("generating the fix: %w", err)
+	}
+
+	report := FixReport{FailedCommand: failed.Command, FailedExitCode: failed.ExitCode}
+	if jsonOutput() {
+		defer writeJSON(&report)
+	}
+
+	return runSuggestion(suggestion, aiAudit("fix", failed.Command), fixAutoConfirm, fixDryRun, &report.DoReport)
+}
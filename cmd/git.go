@@ -4,7 +4,6 @@ import (
 	"bufio"
 	"fmt"
 	"os"
-	"os/exec"
 	"strings"
 
 	"github.com/fatih/color"
@@ -13,6 +12,7 @@ import (
 
 var (
 	gitAutoConfirm bool
+	gitAIMessage   bool
 )
 
 var gitCmd = &cobra.Command{
@@ -25,47 +25,94 @@ This command performs the following actions:
 2. Commits with your message (git commit -m "message")
 3. Pushes to the current branch (git push)
 
-You will be asked for confirmation before execution.`,
-	Args: cobra.MinimumNArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
+Run it without a message (or with --ai) to have the AI read your diff and
+propose a Conventional Commits message that you can accept, edit in $EDITOR
+or regenerate.
+
+You will be asked for confirmation before execution.
+
+Examples:
+  livecli git "fix: handle empty config file"
+  livecli git
+  livecli git --ai --yes`,
+	RunE: func(cmd *cobra.Command, args []string) error {
 		message := strings.Join(args, " ")
-		runGitWorkflow(message)
+		audit := AuditEntry{Source: "git"}
+
+		if message == "" || gitAIMessage {
+			var err error
+			message, err = proposeCommitMessage()
+			if err != nil {
+				return err
+			}
+			if message == "" {
+				color.Yellow("\n❌ Operation cancelled.")
+				return nil
+			}
+			audit = aiAudit("git", "")
+		}
+
+		audit.Task, _, _ = strings.Cut(message, "\n")
+		return runGitWorkflow(message, audit)
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(gitCmd)
 	gitCmd.Flags().BoolVarP(&gitAutoConfirm, "yes", "y", false, "Auto-confirm all git actions")
+	gitCmd.Flags().BoolVar(&gitAIMessage, "ai", false, "Generate the commit message from the diff with AI")
 }
 
-func runGitWorkflow(message string) {
+// runGitWorkflow stages, commits and pushes, recording each git invocation in
+// the audit log based on the audit template. It returns an error when a step
+// fails; cancelling is not one.
+func runGitWorkflow(message string, audit AuditEntry) error {
 	cyan := color.New(color.FgCyan, color.Bold)
 	green := color.New(color.FgGreen, color.Bold)
 	yellow := color.New(color.FgYellow)
 	red := color.New(color.FgRed, color.Bold)
 
-	cyan.Println("\n╔═══════════════════════════════════════════════════════════╗")
-	cyan.Println("║              🚀 Git Workflow Automator                    ║")
-	cyan.Println("╚═══════════════════════════════════════════════════════════╝")
+	printBanner(cyan, cyan, "║              🚀 Git Workflow Automator                    ║")
+
+	// Write the message to a file so that quotes, newlines and shell syntax
+	// reach git verbatim
+	messageFile, err := writeCommitMessage(message)
+	if err != nil {
+		return fmt.Errorf("preparing the commit message: %w", err)
+	}
+	defer os.Remove(messageFile)
 
-	// Define the steps
+	// Define the steps; each runs git directly with an argument vector, no shell
 	steps := []struct {
 		desc string
-		cmd  string
+		args []string
 	}{
-		{"Stage all changes", "git add ."},
-		{"Commit changes", fmt.Sprintf("git commit -m \"%s\"", message)},
-		{"Push to remote", "git push"},
+		{"Stage all changes", []string{"add", "."}},
+		{"Commit changes", []string{"commit", "-F", messageFile}},
+		{"Push to remote", []string{"push"}},
+	}
+
+	// Steps that never run are reported as skipped
+	report := GitReport{Message: message}
+	for _, step := range steps {
+		report.Steps = append(report.Steps, StepReport{
+			Description: step.desc,
+			Command:     displayArgs("git", step.args),
+			Status:      StepSkipped,
+		})
+	}
+	if jsonOutput() {
+		defer writeJSON(&report)
 	}
 
 	// Display plan
-	fmt.Printf("\n📋 Commit Message: %s\n", message)
+	fmt.Printf("\n📋 Commit Message:\n%s\n", indentLines(message, "   "))
 	cyan.Println("\n📝 Execution Plan:")
 	cyan.Println("─────────────────────────────────────────────────────────────")
-	
+
 	for i, step := range steps {
 		fmt.Printf("\n%d. %s\n", i+1, step.desc)
-		color.Magenta("   Command: %s", step.cmd)
+		color.Magenta("   Command: %s", displayArgs("git", step.args))
 	}
 	cyan.Println("\n─────────────────────────────────────────────────────────────")
 
@@ -78,7 +125,7 @@ func runGitWorkflow(message string) {
 
 		if response != "yes" && response != "y" {
 			yellow.Println("\n❌ Operation cancelled.")
-			return
+			return nil
 		}
 	}
 
@@ -91,35 +138,52 @@ func runGitWorkflow(message string) {
 		cyan.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
 
 		// We use a custom execution here to ensure we stop on error
-		err := runCommandWithOutput(step.cmd)
-		if err != nil {
-			red.Printf("\n❌ Step failed: %v\n", err)
+		result := runArgs("git", step.args...)
+
+		audit.Approval = approvalConfirmed
+		if gitAutoConfirm {
+			audit.Approval = approvalAuto
+		}
+		recordAudit(audit, result)
+		report.Steps[i] = newStepReport(step.desc, result)
+
+		if result.Err != nil {
+			red.Printf("\n❌ Step failed: %v\n", result.Err)
 			red.Println("Stopping workflow execution.")
-			return
+			return fmt.Errorf("%s failed: %w", strings.ToLower(step.desc), result.Err)
 		}
 		green.Printf("\n✓ %s completed\n", step.desc)
 	}
+	report.OK = true
 
-	cyan.Println("\n╔═══════════════════════════════════════════════════════════╗")
-	green.Println("║           ✅ Git Workflow Complete!                       ║")
-	cyan.Println("╚═══════════════════════════════════════════════════════════╝")
+	printBanner(cyan, green, "║           ✅ Git Workflow Complete!                       ║")
 	fmt.Println()
+	return nil
+}
+
+// GitReport is the JSON output of the git workflow
+type GitReport struct {
+	Message string       `json:"message"`
+	Steps   []StepReport `json:"steps"`
+	OK      bool         `json:"ok"`
 }
 
-// runCommandWithOutput runs a command and streams output, returning error if it fails
-func runCommandWithOutput(commandStr string) error {
-	// Determine shell
-	shell := "sh"
-	if os.Getenv("SHELL") != "" {
-		shell = os.Getenv("SHELL")
+// writeCommitMessage stores message in a temporary file for "git commit -F"
+func writeCommitMessage(message string) (string, error) {
+	f, err := os.CreateTemp("", "livecli-commit-msg-*.txt")
+	if err != nil {
+		return "", err
 	}
-	
-	var cmd *exec.Cmd
-	// Simple command splitting for basic cases, but using shell for complex ones
-	cmd = exec.Command(shell, "-c", commandStr)
-	
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	
-	return cmd.Run()
+	defer f.Close()
+
+	if _, err := f.WriteString(message + "\n"); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// indentLines prefixes every line of text with indent
+func indentLines(text, indent string) string {
+	return indent + strings.ReplaceAll(text, "\n", "\n"+indent)
 }
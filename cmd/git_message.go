@@ -0,0 +1,250 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// maxDiffBytes bounds how much of the diff is sent to the model
+const maxDiffBytes = 12000
+
+const commitMessagePrompt = `You write git commit messages following the Conventional Commits specification.
+
+Rules:
+1. First line: <type>(<optional scope>): <summary>, at most 72 characters
+2. Types: feat, fix, docs, style, refactor, perf, test, build, ci, chore, revert
+3. Use the imperative mood ("add", not "added")
+4. If the change needs explanation, add a blank line and a short body wrapped at 72 characters
+5. Describe what changed and why, not how
+6. Respond with ONLY the commit message (no markdown fences, no quotes, no commentary)`
+
+// collectDiff returns the changes the git workflow will commit, which stages
+// everything first: staged and unstaged changes to tracked files, plus the
+// names of untracked files
+func collectDiff() (string, error) {
+	// Compare with HEAD so staged and unstaged changes are both included; a
+	// repository without commits has no HEAD, so its two diffs are combined
+	var diff string
+	if _, err := gitOutput("rev-parse", "--verify", "--quiet", "HEAD"); err == nil {
+		if diff, err = gitOutput("diff", "HEAD"); err != nil {
+			return "", err
+		}
+	} else {
+		staged, err := gitOutput("diff", "--staged")
+		if err != nil {
+			return "", err
+		}
+		unstaged, err := gitOutput("diff")
+		if err != nil {
+			return "", err
+		}
+		diff = staged + unstaged
+	}
+
+	untracked, err := gitOutput("ls-files", "--others", "--exclude-standard")
+	if err != nil {
+		return "", err
+	}
+
+	if files := strings.TrimSpace(untracked); files != "" {
+		diff += "\nNew untracked files:\n" + files + "\n"
+	}
+	return diff, nil
+}
+
+// gitOutput runs git with the given arguments and returns its stdout
+func gitOutput(args ...string) (string, error) {
+	out, err := exec.Command("git", args...).Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return string(out), nil
+}
+
+// boundDiff truncates a diff to limit bytes, marking what was left out
+func boundDiff(diff string, limit int) string {
+	if len(diff) <= limit {
+		return diff
+	}
+	return diff[:limit] + fmt.Sprintf("\n[... diff truncated, %d more bytes not shown ...]\n", len(diff)-limit)
+}
+
+// generateCommitMessage asks the model for a Conventional Commits message
+func generateCommitMessage(provider Provider, diff string) (string, error) {
+	resp, err := provider.Chat(context.Background(), ChatRequest{
+		Model: model,
+		Messages: []Message{
+			{
+				Role:    RoleSystem,
+				Content: commitMessagePrompt,
+			},
+			{
+				Role:    RoleUser,
+				Content: "Write a commit message for this diff:\n\n" + boundDiff(diff, maxDiffBytes),
+			},
+		},
+		Temperature: 0.3,
+		MaxTokens:   300,
+	})
+	if err != nil {
+		return "", fmt.Errorf("AI request failed: %w", err)
+	}
+
+	message := strings.TrimSpace(resp.Content)
+	message = strings.TrimPrefix(message, "```")
+	message = strings.TrimSuffix(message, "```")
+	message = strings.Trim(strings.TrimSpace(message), "\"")
+	if message == "" {
+		return "", fmt.Errorf("AI returned an empty commit message")
+	}
+	return message, nil
+}
+
+// proposeCommitMessage generates a message and lets the user accept, edit or
+// regenerate it. It returns an empty string if the user cancels.
+func proposeCommitMessage() (string, error) {
+	provider, err := newProvider()
+	if err != nil {
+		return "", err
+	}
+
+	diff, err := collectDiff()
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(diff) == "" {
+		return "", fmt.Errorf("no changes to commit")
+	}
+
+	cyan := color.New(color.FgCyan, color.Bold)
+	yellow := color.New(color.FgYellow)
+	reader := bufio.NewReader(os.Stdin)
+
+	message := ""
+	for {
+		if message == "" {
+			yellow.Println("\n⏳ Generating commit message from your changes...")
+
+			message, err = generateCommitMessage(provider, diff)
+			if err != nil {
+				return "", err
+			}
+		}
+
+		cyan.Println("\n📝 Proposed commit message:")
+		cyan.Println("─────────────────────────────────────────────────────────────")
+		fmt.Println(message)
+		cyan.Println("─────────────────────────────────────────────────────────────")
+
+		if gitAutoConfirm {
+			return message, nil
+		}
+
+		response, err := promptCommitAction(reader)
+		if err != nil {
+			return "", err
+		}
+
+		switch response {
+		case "a", "accept", "y", "yes":
+			return message, nil
+		case "e", "edit":
+			edited, err := editInEditor(message)
+			if err != nil {
+				color.Red("Error opening editor: %v", err)
+			} else if edited == "" {
+				yellow.Println("Empty message, keeping the previous one")
+			} else {
+				message = edited
+			}
+		case "r", "regenerate":
+			message = ""
+		case "c", "cancel", "n", "no":
+			return "", nil
+		}
+	}
+}
+
+// promptCommitAction asks what to do with the proposed message until it gets
+// a known answer. Closed or unreadable input is an error, not a cancel, so
+// scripts without --yes fail instead of waiting forever.
+func promptCommitAction(reader *bufio.Reader) (string, error) {
+	for {
+		fmt.Print("\n❓ [a]ccept, [e]dit, [r]egenerate or [c]ancel? ")
+		response, err := reader.ReadString('\n')
+		if err != nil && response == "" {
+			fmt.Println()
+			return "", fmt.Errorf("no answer to the commit message prompt: %w (use --yes to accept it)", err)
+		}
+
+		response = strings.TrimSpace(strings.ToLower(response))
+		switch response {
+		case "a", "accept", "y", "yes", "e", "edit", "r", "regenerate", "c", "cancel", "n", "no":
+			return response, nil
+		case "":
+		default:
+			color.Yellow("Please answer a, e, r or c")
+		}
+	}
+}
+
+// editInEditor opens text in $EDITOR and returns the saved result with
+// comment lines removed, like git does
+func editInEditor(text string) (string, error) {
+	f, err := os.CreateTemp("", "livecli-commit-*.txt")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(f.Name())
+
+	content := text + "\n\n# Edit the commit message above. Lines starting with '#' are ignored.\n"
+	if _, err := f.WriteString(content); err != nil {
+		f.Close()
+		return "", err
+	}
+	f.Close()
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+		if runtime.GOOS == "windows" {
+			editor = "notepad"
+		}
+	}
+
+	// $EDITOR may carry arguments, e.g. "code --wait"
+	parts := strings.Fields(editor)
+	cmd := exec.Command(parts[0], append(parts[1:], f.Name())...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", err
+	}
+
+	data, err := os.ReadFile(f.Name())
+	if err != nil {
+		return "", err
+	}
+
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n")), nil
+}
@@ -1,13 +1,12 @@
 package cmd
 
 import (
-	"context"
 	"fmt"
+	"os"
+	"os/signal"
 	"strings"
 
-	"github.com/chzyer/readline"
 	"github.com/fatih/color"
-	openai "github.com/sashabaranov/go-openai"
 	"github.com/spf13/cobra"
 )
 
@@ -16,128 +15,148 @@ var interactiveCmd = &cobra.Command{
 	Short: "Start interactive mode (combines exec and chat)",
 	Long: `Start interactive mode where you can execute commands or chat with AI.
 	
-Commands starting with '/' are executed as system commands.
+Commands starting with '!' (or '/run <command>') are executed as system commands.
 Commands starting with '@' are sent to AI as questions.
 Everything else is treated as a chat message.
 
+The command, its output and exit code are added to the chat history, so you
+can follow up with questions like "why did that fail?". Disable this with
+--share-output=false.
+
 Special commands:
+  !<command>       - Run a system command
+  /run <command>   - Run a system command
   @ask <question>  - Ask AI a question
-  /clear          - Clear chat history
-  /exit or /quit  - Exit interactive mode`,
-	Run: func(cmd *cobra.Command, args []string) {
-		startInteractiveMode()
+  /help            - List all commands (shared with chat)
+  /clear           - Clear chat history
+  /exit or /quit   - Exit interactive mode`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return startInteractiveMode()
 	},
 }
 
+var shareCommandOutput bool
+
+// maxSharedOutput bounds how much command output is copied into the chat history
+const maxSharedOutput = 4000
+
 func init() {
 	rootCmd.AddCommand(interactiveCmd)
+
+	interactiveCmd.Flags().
+		BoolVar(&shareCommandOutput, "share-output", true, "Add executed commands and their output to the chat history")
+	addContextFlags(interactiveCmd)
 }
 
-func startInteractiveMode() {
-	if apiKey == "" {
-		color.Red(
-			"Error: OpenAI API key not set. Use --api-key flag or set OPENAI_API_KEY environment variable.",
-		)
-		return
+func startInteractiveMode() error {
+	provider, err := newProvider()
+	if err != nil {
+		return err
 	}
 
-	ctx := context.Background()
-	client := openai.NewClient(apiKey)
+	if err := validateContextStrategy(); err != nil {
+		return err
+	}
 
-	// Maintain conversation history
-	messages := []openai.ChatCompletionMessage{
-		{
-			Role:    openai.ChatMessageRoleSystem,
-			Content: systemPrompt,
+	r := newREPL(provider, "> ")
+	r.echoInput = true
+	r.goodbye = "👋 Exiting interactive mode. Goodbye!"
+
+	r.register(&slashCommand{
+		name:        "run",
+		args:        "<command>",
+		description: "Run a system command",
+		run: func(r *repl, args string) error {
+			if args == "" {
+				return fmt.Errorf("usage: /run <command>")
+			}
+			runREPLCommand(r, args)
+			return nil
 		},
-	}
+	})
+
+	// Handle system commands
+	r.handle(func(r *repl, input string) bool {
+		if !strings.HasPrefix(input, "!") {
+			return false
+		}
+		command := strings.TrimSpace(strings.TrimPrefix(input, "!"))
+		if command == "" {
+			color.Yellow("Usage: !<command> or /run <command>")
+			return true
+		}
+		runREPLCommand(r, command)
+		return true
+	})
+
+	// Handle quick question
+	r.handle(func(r *repl, input string) bool {
+		if !strings.HasPrefix(input, "@ask ") {
+			return false
+		}
+		if err := askQuestion(strings.TrimPrefix(input, "@ask ")); err != nil {
+			color.Red("Error: %v", err)
+		}
+		return true
+	})
 
 	cyan := color.New(color.FgCyan, color.Bold)
-	green := color.New(color.FgGreen, color.Bold)
 	yellow := color.New(color.FgYellow)
-	magenta := color.New(color.FgMagenta, color.Bold)
 
-	cyan.Println("\n╔═══════════════════════════════════════════════════════════╗")
-	cyan.Println("║         🎮 Interactive Mode - LiveCLI                     ║")
-	cyan.Println("╚═══════════════════════════════════════════════════════════╝")
+	printBanner(cyan, cyan, "║         🎮 Interactive Mode - LiveCLI                     ║")
 
 	fmt.Println("\nMode Guide:")
+	yellow.Println("  !<command>       → Run a system command")
+	yellow.Println("  /run <command>   → Run a system command")
 	yellow.Println("  @ask <question>  → Ask AI a quick question")
 	yellow.Println("  <message>        → Chat with AI")
+	yellow.Println("  /help            → List all commands")
 	yellow.Println("  /clear           → Clear chat history")
 	yellow.Println("  /exit            → Exit interactive mode")
 	fmt.Println()
 
-	// Setup readline
-	rl, err := readline.New("> ")
-	if err != nil {
-		color.Red("Error initializing readline: %v", err)
-		return
-	}
-	defer rl.Close()
-
-	for {
-		line, err := rl.Readline()
-		if err != nil {
-			break
-		}
-
-		input := strings.TrimSpace(line)
-
-		if input == "" {
-			continue
-		}
-
-		// Handle exit
-		if input == "/exit" || input == "/quit" {
-			green.Println("\n👋 Exiting interactive mode. Goodbye!")
-			break
-		}
-
-		// Handle clear
-		if input == "/clear" {
-			messages = []openai.ChatCompletionMessage{
-				{
-					Role:    openai.ChatMessageRoleSystem,
-					Content: systemPrompt,
-				},
-			}
-			green.Println("✓ Chat history cleared")
-			continue
-		}
+	return r.run()
+}
 
-		// Handle quick question
-		if strings.HasPrefix(input, "@ask ") {
-			question := strings.TrimPrefix(input, "@ask ")
-			askQuestion(question)
-			continue
-		}
+// runREPLCommand runs a command and optionally shares the result with the AI
+func runREPLCommand(r *repl, command string) {
+	result := runInteractiveCommand(command)
+	if shareCommandOutput {
+		r.messages = append(r.messages, Message{
+			Role:    RoleUser,
+			Content: describeCommandResult(result),
+		})
+	}
+}
 
-		// Handle AI chat
-		magenta.Printf("\nYou: %s\n", input)
+// runInteractiveCommand runs a command from the REPL. Ctrl+C is delivered to
+// the command only, so interrupting it does not end the session.
+func runInteractiveCommand(command string) StepResult {
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
 
-		// Add user message to history
-		messages = append(messages, openai.ChatCompletionMessage{
-			Role:    openai.ChatMessageRoleUser,
-			Content: input,
-		})
+	result := runCommand(command)
+	recordAudit(AuditEntry{Source: "interactive", Approval: approvalUser}, result)
 
-		fmt.Print("AI> ")
-		response, err := getOpenAIResponse(ctx, client, messages)
-		if err != nil {
-			color.Red("Error: %v\n", err)
-			// Remove the last user message if there was an error
-			messages = messages[:len(messages)-1]
-			continue
-		}
+	if result.Status == StepSucceeded {
+		color.Green("✓ exit code 0 (%s)\n", formatDuration(result.Duration))
+	} else {
+		color.Red("✗ exit code %d (%s)\n", result.ExitCode, formatDuration(result.Duration))
+	}
+	return result
+}
 
-		// Add assistant response to history
-		messages = append(messages, openai.ChatCompletionMessage{
-			Role:    openai.ChatMessageRoleAssistant,
-			Content: response,
-		})
+// describeCommandResult renders a command run as chat context for the model
+func describeCommandResult(result StepResult) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "I ran the command `%s`. It exited with code %d.\n", result.Command, result.ExitCode)
 
-		fmt.Println(response)
-		fmt.Println()
+	if out := truncateOutput(result.Stdout, maxSharedOutput); out != "" {
+		fmt.Fprintf(&b, "\nstdout:\n```\n%s\n```\n", out)
+	}
+	if errOut := truncateOutput(result.Stderr, maxSharedOutput); errOut != "" {
+		fmt.Fprintf(&b, "\nstderr:\n```\n%s\n```\n", errOut)
 	}
+	return b.String()
 }
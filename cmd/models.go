@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var modelsCmd = &cobra.Command{
+	Use:   "models",
+	Short: "List models available from the selected provider",
+	Long: `List the models exposed by the selected LLM provider.
+
+Examples:
+  livecli models
+  livecli models --provider ollama
+  livecli models --provider openai-compatible --base-url http://10.0.0.5:8000/v1`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return listModels()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(modelsCmd)
+}
+
+func listModels() error {
+	provider, err := newProvider()
+	if err != nil {
+		return err
+	}
+
+	models, err := provider.ListModels(context.Background())
+	if err != nil {
+		return fmt.Errorf("listing models: %w", err)
+	}
+
+	sort.Strings(models)
+
+	cyan := color.New(color.FgCyan, color.Bold)
+	cyan.Printf("\n📦 Models available from %s:\n\n", provider.Name())
+	for _, m := range models {
+		if m == model {
+			color.Green("  * %s", m)
+			continue
+		}
+		fmt.Printf("    %s\n", m)
+	}
+	fmt.Println()
+	return nil
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// Output formats selected with --output
+const (
+	outputText  = "text"
+	outputPlain = "plain"
+	outputJSON  = "json"
+)
+
+var outputFormat string
+
+// resultOut receives machine-readable results. In JSON mode os.Stdout is
+// pointed at stderr, so progress, prompts and command output never mix with
+// the JSON document written here.
+var resultOut io.Writer = os.Stdout
+
+// configureOutput resolves --output. Without the flag, output is plain when
+// stdout is not a terminal, e.g. when piped into another program.
+func configureOutput(cmd *cobra.Command) error {
+	switch outputFormat {
+	case "":
+		outputFormat = outputText
+		if !isTerminal(os.Stdout) {
+			outputFormat = outputPlain
+		}
+	case outputText, outputPlain, outputJSON:
+	default:
+		return fmt.Errorf("invalid --output %q (use text, plain or json)", outputFormat)
+	}
+
+	if outputFormat != outputText {
+		color.NoColor = true
+	}
+	if outputFormat == outputJSON {
+		divertStdout(cmd)
+	}
+	return nil
+}
+
+// divertStdout points os.Stdout at stderr, leaving the real stdout to results
+// written to resultOut
+func divertStdout(cmd *cobra.Command) {
+	if resultOut != os.Stdout {
+		return
+	}
+	resultOut = os.Stdout
+	os.Stdout = os.Stderr
+	color.Output = os.Stderr
+	cmd.SetOut(os.Stderr)
+}
+
+// decoratedOutput reports whether banners should be drawn
+func decoratedOutput() bool {
+	return outputFormat == outputText
+}
+
+// printBanner draws a boxed title line, e.g. "║  🤖 Title  ║", with a border
+// in text mode and just the title otherwise
+func printBanner(border, title *color.Color, line string) {
+	if !decoratedOutput() {
+		title.Printf("\n%s\n", strings.TrimSpace(strings.Trim(line, "║")))
+		return
+	}
+	border.Println("\n╔═══════════════════════════════════════════════════════════╗")
+	title.Println(line)
+	border.Println("╚═══════════════════════════════════════════════════════════╝")
+}
+
+func jsonOutput() bool {
+	return outputFormat == outputJSON
+}
+
+// writeJSON writes v as the command's JSON result
+func writeJSON(v any) {
+	enc := json.NewEncoder(resultOut)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		color.Red("Error writing JSON output: %v", err)
+	}
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// StepReport is how an executed command appears in JSON output
+type StepReport struct {
+	Description string     `json:"description,omitempty"`
+	Command     string     `json:"command"`
+	Status      StepStatus `json:"status"`
+	ExitCode    int        `json:"exit_code"`
+	DurationMs  int64      `json:"duration_ms"`
+	Stdout      string     `json:"stdout,omitempty"`
+	Stderr      string     `json:"stderr,omitempty"`
+}
+
+func newStepReport(description string, result StepResult) StepReport {
+	return StepReport{
+		Description: description,
+		Command:     result.Command,
+		Status:      result.Status,
+		ExitCode:    result.ExitCode,
+		DurationMs:  result.Duration.Milliseconds(),
+		Stdout:      result.Stdout,
+		Stderr:      result.Stderr,
+	}
+}
@@ -0,0 +1,234 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// maxPlanAttempts bounds how often the model is asked for a valid response
+const maxPlanAttempts = 3
+
+// requestPlan asks the model for a setup plan in JSON mode and re-prompts it
+// with the validation error until it returns a valid plan
+func requestPlan(provider Provider, messages []Message) (SetupPlan, error) {
+	var plan SetupPlan
+	err := requestJSON(provider, messages, "plan", func(content string) (err error) {
+		plan, err = parseSetupPlan(content)
+		return err
+	})
+	return plan, err
+}
+
+// requestJSON asks the model for a JSON response and re-prompts it with the
+// error returned by parse until parse accepts a response. what names the
+// expected object in messages, e.g. "plan".
+func requestJSON(provider Provider, messages []Message, what string, parse func(content string) error) error {
+	var lastErr error
+	for attempt := 1; attempt <= maxPlanAttempts; attempt++ {
+		resp, err := provider.Chat(context.Background(), ChatRequest{
+			Model:       model,
+			Messages:    messages,
+			Temperature: 0.3, // Lower temperature for more consistent output
+			MaxTokens:   2000,
+			JSON:        true,
+		})
+		if err != nil {
+			return fmt.Errorf("AI request failed: %w", err)
+		}
+
+		err = parse(resp.Content)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+
+		if attempt < maxPlanAttempts {
+			color.Yellow("⚠️  The AI returned an invalid %s (%v), asking it to correct it...", what, err)
+		}
+
+		messages = append(messages,
+			Message{Role: RoleAssistant, Content: resp.Content},
+			Message{
+				Role: RoleUser,
+				Content: fmt.Sprintf("That response is not a valid %s: %v\n\n"+
+					"Respond again with ONLY the corrected JSON object in the required format.", what, err),
+			},
+		)
+	}
+	return fmt.Errorf("no valid %s after %d attempts: %w", what, maxPlanAttempts, lastErr)
+}
+
+// parseSetupPlan finds the plan object in a model response, which may be
+// wrapped in markdown fences or prose, and validates it
+func parseSetupPlan(content string) (SetupPlan, error) {
+	objects := jsonObjects(content)
+	if len(objects) == 0 {
+		return SetupPlan{}, fmt.Errorf("the response contains no JSON object")
+	}
+
+	for _, object := range objects {
+		var raw struct {
+			Diagnosis string       `json:"diagnosis"`
+			Steps     *[]SetupStep `json:"steps"`
+		}
+		if err := json.Unmarshal([]byte(object), &raw); err != nil {
+			return SetupPlan{}, fmt.Errorf("invalid plan JSON: %w", err)
+		}
+		if raw.Steps == nil {
+			continue
+		}
+
+		plan := SetupPlan{Diagnosis: strings.TrimSpace(raw.Diagnosis), Steps: *raw.Steps}
+		if err := validatePlan(&plan); err != nil {
+			return SetupPlan{}, err
+		}
+		return plan, nil
+	}
+	return SetupPlan{}, fmt.Errorf(`the JSON object has no "steps" array`)
+}
+
+// validatePlan trims every step and checks the plan schema: each step needs a
+// command and a description, and no command may appear twice
+func validatePlan(plan *SetupPlan) error {
+	var problems []string
+	seen := map[string]int{}
+
+	for i := range plan.Steps {
+		step := &plan.Steps[i]
+		step.Command = strings.TrimSpace(step.Command)
+		step.Description = strings.TrimSpace(step.Description)
+		step.Undo = strings.TrimSpace(step.Undo)
+		step.Check = strings.TrimSpace(step.Check)
+
+		if step.Command == "" {
+			problems = append(problems, fmt.Sprintf("step %d has an empty command", i+1))
+		} else if first, ok := seen[step.Command]; ok {
+			problems = append(problems, fmt.Sprintf("step %d duplicates step %d (%s)", i+1, first, step.Command))
+		} else {
+			seen[step.Command] = i + 1
+		}
+
+		if step.Description == "" {
+			problems = append(problems, fmt.Sprintf("step %d has an empty description", i+1))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid plan: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// jsonObjects returns the top-level JSON objects embedded in text, in order
+func jsonObjects(text string) []string {
+	var objects []string
+	for start := strings.IndexByte(text, '{'); start >= 0; {
+		end := matchingBrace(text, start)
+		if end > 0 && json.Valid([]byte(text[start:end+1])) {
+			objects = append(objects, text[start:end+1])
+			start = end + 1
+		} else {
+			start++
+		}
+
+		next := strings.IndexByte(text[start:], '{')
+		if next < 0 {
+			break
+		}
+		start += next
+	}
+	return objects
+}
+
+// matchingBrace returns the index of the brace closing the one at start, or -1.
+// Braces inside JSON strings are ignored.
+func matchingBrace(text string, start int) int {
+	depth := 0
+	inString := false
+	escaped := false
+
+	for i := start; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case escaped:
+			escaped = false
+		case inString:
+			if c == '\\' {
+				escaped = true
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// planFileVersion is the format version written to saved plans
+const planFileVersion = 1
+
+// PlanFile is a reviewed setup plan saved for replay on other machines
+type PlanFile struct {
+	Version   int         `json:"version"`
+	Task      string      `json:"task"`
+	OS        string      `json:"os"`
+	Provider  string      `json:"provider"`
+	Model     string      `json:"model"`
+	CreatedAt time.Time   `json:"created_at"`
+	Steps     []SetupStep `json:"steps"`
+}
+
+// savePlanFile writes a plan and the context it was generated in to path
+func savePlanFile(path, task string, plan SetupPlan) error {
+	data, err := json.MarshalIndent(PlanFile{
+		Version:   planFileVersion,
+		Task:      task,
+		OS:        detectOS(),
+		Provider:  providerName,
+		Model:     model,
+		CreatedAt: time.Now(),
+		Steps:     plan.Steps,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// loadPlanFile reads and validates a saved plan
+func loadPlanFile(path string) (*PlanFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file PlanFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse plan %s: %w", path, err)
+	}
+	if file.Version > planFileVersion {
+		return nil, fmt.Errorf("plan %s has format version %d; this livecli supports up to %d",
+			path, file.Version, planFileVersion)
+	}
+
+	plan := SetupPlan{Steps: file.Steps}
+	if err := validatePlan(&plan); err != nil {
+		return nil, fmt.Errorf("plan %s: %w", path, err)
+	}
+	file.Steps = plan.Steps
+	return &file, nil
+}
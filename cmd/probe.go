@@ -0,0 +1,241 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// SystemInfo describes the machine a setup plan is generated for
+type SystemInfo struct {
+	OS              string
+	Arch            string
+	Distro          string   // os-release ID, e.g. "ubuntu"
+	DistroLike      []string // os-release ID_LIKE, e.g. ["debian"]
+	Name            string   // human readable name, e.g. "Ubuntu 22.04.4 LTS"
+	Version         string
+	PackageManagers []string
+	Privileges      string
+	Shell           string
+	Container       string
+	WSL             bool
+	Installed       []string
+}
+
+// packageManagers are checked on PATH in this order
+var packageManagers = []string{
+	"apt", "dnf", "yum", "pacman", "zypper", "apk", "brew", "port",
+	"snap", "flatpak", "nix", "winget", "choco", "scoop",
+}
+
+// knownTools are commonly requested tools reported when already installed
+var knownTools = []string{
+	"git", "curl", "wget", "make", "gcc", "clang",
+	"docker", "podman", "kubectl", "helm", "terraform",
+	"node", "npm", "yarn", "pnpm", "deno", "bun",
+	"python3", "pip3", "pipx", "go", "rustc", "cargo", "java", "ruby", "php", "dotnet",
+	"psql", "mysql", "redis-server", "code", "vim", "nvim",
+}
+
+var (
+	probeOnce sync.Once
+	probed    SystemInfo
+)
+
+// probeSystem inspects the machine once per process
+func probeSystem() SystemInfo {
+	probeOnce.Do(func() {
+		probed = SystemInfo{
+			OS:   runtime.GOOS,
+			Arch: runtime.GOARCH,
+		}
+		probed.probeRelease()
+		probed.probeEnvironment()
+
+		for _, pm := range packageManagers {
+			if _, err := exec.LookPath(pm); err == nil {
+				probed.PackageManagers = append(probed.PackageManagers, pm)
+			}
+		}
+		for _, tool := range knownTools {
+			if _, err := exec.LookPath(tool); err == nil {
+				probed.Installed = append(probed.Installed, tool)
+			}
+		}
+	})
+	return probed
+}
+
+// probeRelease fills in the distribution name and version
+func (s *SystemInfo) probeRelease() {
+	switch s.OS {
+	case "linux":
+		release := readOSRelease()
+		s.Distro = release["ID"]
+		s.DistroLike = strings.Fields(release["ID_LIKE"])
+		s.Version = release["VERSION_ID"]
+		s.Name = release["PRETTY_NAME"]
+		if s.Name == "" {
+			s.Name = strings.TrimSpace(release["NAME"] + " " + s.Version)
+		}
+	case "darwin":
+		s.Distro = "macos"
+		if out, err := exec.Command("sw_vers", "-productVersion").Output(); err == nil {
+			s.Version = strings.TrimSpace(string(out))
+		}
+		s.Name = strings.TrimSpace("macOS " + s.Version)
+	case "windows":
+		s.Distro = "windows"
+		s.Name = "Windows"
+	}
+	if s.Name == "" {
+		s.Name = s.OS
+	}
+}
+
+// readOSRelease parses /etc/os-release into a key/value map
+func readOSRelease() map[string]string {
+	values := map[string]string{}
+	f, err := os.Open("/etc/os-release")
+	if err != nil {
+		return values
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+		if !ok || strings.HasPrefix(key, "#") {
+			continue
+		}
+		values[key] = strings.Trim(value, `"'`)
+	}
+	return values
+}
+
+// probeEnvironment detects privileges, the shell and virtualized environments
+func (s *SystemInfo) probeEnvironment() {
+	s.Shell = filepath.Base(os.Getenv("SHELL"))
+	if s.Shell == "." {
+		s.Shell = ""
+	}
+	if s.OS == "windows" {
+		s.Shell = "cmd"
+		if os.Getenv("PSModulePath") != "" {
+			s.Shell = "cmd (PowerShell available)"
+		}
+		s.Privileges = "unknown (Windows)"
+		return
+	}
+
+	switch {
+	case os.Geteuid() == 0:
+		s.Privileges = "running as root, sudo is not needed"
+	case !onPath("sudo"):
+		s.Privileges = "sudo is not installed, avoid commands that need root"
+	case exec.Command("sudo", "-n", "true").Run() == nil:
+		s.Privileges = "sudo available without a password"
+	default:
+		s.Privileges = "sudo available (will prompt for a password)"
+	}
+
+	if s.OS != "linux" {
+		return
+	}
+
+	switch {
+	case fileExists("/.dockerenv"):
+		s.Container = "docker"
+	case fileExists("/run/.containerenv"):
+		s.Container = "podman"
+	case os.Getenv("container") != "":
+		s.Container = os.Getenv("container")
+	default:
+		if data, err := os.ReadFile("/proc/1/cgroup"); err == nil {
+			cgroup := string(data)
+			for _, name := range []string{"docker", "kubepods", "lxc", "containerd"} {
+				if strings.Contains(cgroup, name) {
+					s.Container = name
+					break
+				}
+			}
+		}
+	}
+
+	if os.Getenv("WSL_DISTRO_NAME") != "" {
+		s.WSL = true
+	} else if data, err := os.ReadFile("/proc/sys/kernel/osrelease"); err == nil {
+		s.WSL = strings.Contains(strings.ToLower(string(data)), "microsoft")
+	}
+}
+
+// Summary returns a one-line description for display
+func (s SystemInfo) Summary() string {
+	parts := []string{s.Name, s.Arch}
+	if len(s.PackageManagers) > 0 {
+		parts = append(parts, strings.Join(s.PackageManagers, ", "))
+	}
+	if s.Container != "" {
+		parts = append(parts, s.Container+" container")
+	}
+	if s.WSL {
+		parts = append(parts, "WSL")
+	}
+	return strings.Join(parts, " · ")
+}
+
+// PromptContext describes the system for the model
+func (s SystemInfo) PromptContext() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "- OS: %s (%s/%s)\n", s.Name, s.OS, s.Arch)
+	if s.Distro != "" {
+		distro := s.Distro
+		if s.Version != "" {
+			distro += " " + s.Version
+		}
+		if len(s.DistroLike) > 0 {
+			distro += ", like " + strings.Join(s.DistroLike, " ")
+		}
+		fmt.Fprintf(&b, "- Distribution: %s\n", distro)
+	}
+
+	managers := "none detected"
+	if len(s.PackageManagers) > 0 {
+		managers = strings.Join(s.PackageManagers, ", ")
+	}
+	fmt.Fprintf(&b, "- Package managers: %s\n", managers)
+	fmt.Fprintf(&b, "- Privileges: %s\n", s.Privileges)
+
+	if s.Shell != "" {
+		fmt.Fprintf(&b, "- Shell: %s\n", s.Shell)
+	}
+	if s.Container != "" {
+		fmt.Fprintf(&b, "- Running inside a %s container (no systemd services, changes may not persist)\n", s.Container)
+	}
+	if s.WSL {
+		b.WriteString("- Running under Windows Subsystem for Linux\n")
+	}
+
+	installed := "none of the common tools"
+	if len(s.Installed) > 0 {
+		installed = strings.Join(s.Installed, ", ")
+	}
+	fmt.Fprintf(&b, "- Already installed: %s\n", installed)
+	return b.String()
+}
+
+func onPath(name string) bool {
+	_, err := exec.LookPath(name)
+	return err == nil
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Chat message roles understood by every provider
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+	RoleTool      = "tool"
+)
+
+// Message is a single provider-neutral chat message
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+	// ToolCalls are the tools an assistant message asks to run
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+	// ToolCallID links a tool message to the call it answers
+	ToolCallID string `json:"tool_call_id,omitempty"`
+}
+
+// Tool is a function the model may call
+type Tool struct {
+	Name        string
+	Description string
+	// Parameters is the JSON schema of the arguments object
+	Parameters json.RawMessage
+}
+
+// ToolCall is a request from the model to run a tool
+type ToolCall struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// Arguments is a JSON object, as generated by the model
+	Arguments string `json:"arguments"`
+}
+
+// ChatRequest is a provider-neutral chat completion request
+type ChatRequest struct {
+	Model       string
+	Messages    []Message
+	Temperature float64
+	MaxTokens   int
+	// JSON asks for a single JSON object as the response. Providers without
+	// a JSON mode ignore it, so callers must still validate the output.
+	JSON bool
+	// Tools the model may call instead of, or before, answering
+	Tools []Tool
+}
+
+// Usage reports token consumption for a completion when the provider returns it
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// ChatResponse is the result of a chat completion
+type ChatResponse struct {
+	Content   string
+	ToolCalls []ToolCall
+	Usage     Usage
+}
+
+// errToolsUnsupported is returned by providers without OpenAI-style tool calling
+var errToolsUnsupported = errors.New("this provider does not support tool calling")
+
+// Provider is an LLM backend capable of chat completion, streaming and model listing
+type Provider interface {
+	// Name returns the provider identifier, e.g. "openai" or "ollama"
+	Name() string
+	// Chat returns the full completion for a request
+	Chat(ctx context.Context, req ChatRequest) (ChatResponse, error)
+	// ChatStream calls onToken for every content fragment as it arrives and
+	// returns the assembled completion once the stream ends
+	ChatStream(ctx context.Context, req ChatRequest, onToken func(string)) (ChatResponse, error)
+	// ListModels returns the model identifiers available to the caller
+	ListModels(ctx context.Context) ([]string, error)
+	// SupportsTools reports whether requests may carry Tools; providers that
+	// can't return errToolsUnsupported for them
+	SupportsTools() bool
+}
+
+// providerSpec describes how to reach a supported provider
+type providerSpec struct {
+	baseURL      string
+	apiKeyEnv    string
+	keyRequired  bool
+	defaultModel string
+	anthropic    bool
+	// jsonMode marks OpenAI-style servers known to accept response_format
+	jsonMode bool
+}
+
+var providerSpecs = map[string]providerSpec{
+	"openai": {
+		baseURL:      "https://api.openai.com/v1",
+		apiKeyEnv:    "OPENAI_API_KEY",
+		keyRequired:  true,
+		defaultModel: "gpt-4o-mini",
+		jsonMode:     true,
+	},
+	"openai-compatible": {
+		apiKeyEnv:    "OPENAI_API_KEY",
+		defaultModel: "gpt-4o-mini",
+	},
+	"gemini": {
+		baseURL:      "https://generativelanguage.googleapis.com/v1beta/openai",
+		apiKeyEnv:    "GEMINI_API_KEY",
+		keyRequired:  true,
+		defaultModel: "gemini-2.5-flash",
+		jsonMode:     true,
+	},
+	"anthropic": {
+		baseURL:      "https://api.anthropic.com/v1",
+		apiKeyEnv:    "ANTHROPIC_API_KEY",
+		keyRequired:  true,
+		defaultModel: "claude-3-5-haiku-latest",
+		anthropic:    true,
+	},
+	"ollama": {
+		baseURL:      "http://localhost:11434/v1",
+		defaultModel: "llama3.1",
+		jsonMode:     true,
+	},
+	"llamacpp": {
+		baseURL:      "http://localhost:8080/v1",
+		defaultModel: "default",
+		jsonMode:     true,
+	},
+}
+
+// providerNames returns the supported provider identifiers in sorted order
+func providerNames() []string {
+	names := make([]string, 0, len(providerSpecs))
+	for name := range providerSpecs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// lookupProvider returns the spec for the selected provider
+func lookupProvider(name string) (providerSpec, error) {
+	spec, ok := providerSpecs[strings.ToLower(name)]
+	if !ok {
+		return providerSpec{}, fmt.Errorf(
+			"unknown provider %q (available: %s)",
+			name,
+			strings.Join(providerNames(), ", "),
+		)
+	}
+	return spec, nil
+}
+
+// resolveAPIKey returns the --api-key value or the provider's environment variable
+func resolveAPIKey(spec providerSpec) string {
+	if apiKey != "" {
+		return apiKey
+	}
+	if spec.apiKeyEnv != "" {
+		return os.Getenv(spec.apiKeyEnv)
+	}
+	return ""
+}
+
+// newProvider builds the provider selected by --provider and --base-url
+func newProvider() (Provider, error) {
+	name := strings.ToLower(providerName)
+	spec, err := lookupProvider(name)
+	if err != nil {
+		return nil, err
+	}
+
+	key := resolveAPIKey(spec)
+	if key == "" && spec.keyRequired {
+		return nil, fmt.Errorf(
+			"API key not set for %s. Use --api-key flag or set %s environment variable",
+			name,
+			spec.apiKeyEnv,
+		)
+	}
+
+	url := spec.baseURL
+	if baseURL != "" {
+		url = baseURL
+	}
+	if url == "" {
+		return nil, fmt.Errorf("provider %s requires --base-url", name)
+	}
+
+	if spec.anthropic {
+		return newAnthropicProvider(key, url), nil
+	}
+	return newOpenAIProvider(name, key, url, spec.jsonMode), nil
+}
+
+// defaultModelFor returns the model used when --model is not given
+func defaultModelFor(name string) string {
+	spec, err := lookupProvider(name)
+	if err != nil {
+		return ""
+	}
+	return spec.defaultModel
+}
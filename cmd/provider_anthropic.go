@@ -0,0 +1,283 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const anthropicVersion = "2023-06-01"
+
+// anthropicProvider talks to the Anthropic Messages API
+type anthropicProvider struct {
+	key     string
+	baseURL string
+	http    *http.Client
+}
+
+func newAnthropicProvider(key, url string) *anthropicProvider {
+	return &anthropicProvider{
+		key:     key,
+		baseURL: strings.TrimSuffix(url, "/"),
+		http:    http.DefaultClient,
+	}
+}
+
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type anthropicRequest struct {
+	Model       string             `json:"model"`
+	System      string             `json:"system,omitempty"`
+	Messages    []anthropicMessage `json:"messages"`
+	MaxTokens   int                `json:"max_tokens"`
+	Temperature float64            `json:"temperature"`
+	Stream      bool               `json:"stream,omitempty"`
+}
+
+type anthropicUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+type anthropicResponse struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	Usage anthropicUsage `json:"usage"`
+}
+
+type anthropicError struct {
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (p *anthropicProvider) Name() string {
+	return "anthropic"
+}
+
+func (p *anthropicProvider) SupportsTools() bool {
+	return false
+}
+
+// jsonPrefill starts the assistant turn so that Claude continues with a JSON
+// object; the Messages API has no dedicated JSON mode
+const jsonPrefill = "{"
+
+// buildRequest moves system messages into the dedicated system field, which
+// the Messages API requires
+func (p *anthropicProvider) buildRequest(req ChatRequest, stream bool) anthropicRequest {
+	var system []string
+	messages := make([]anthropicMessage, 0, len(req.Messages))
+
+	for _, msg := range req.Messages {
+		if msg.Role == RoleSystem {
+			system = append(system, msg.Content)
+			continue
+		}
+		messages = append(messages, anthropicMessage{Role: msg.Role, Content: msg.Content})
+	}
+	if req.JSON {
+		messages = append(messages, anthropicMessage{Role: RoleAssistant, Content: jsonPrefill})
+	}
+
+	maxTokens := req.MaxTokens
+	if maxTokens <= 0 {
+		maxTokens = 1024
+	}
+
+	return anthropicRequest{
+		Model:       req.Model,
+		System:      strings.Join(system, "\n\n"),
+		Messages:    messages,
+		MaxTokens:   maxTokens,
+		Temperature: req.Temperature,
+		Stream:      stream,
+	}
+}
+
+func (p *anthropicProvider) do(ctx context.Context, method, path string, body any) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, method, p.baseURL+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("x-api-key", p.key)
+	httpReq.Header.Set("anthropic-version", anthropicVersion)
+	httpReq.Header.Set("content-type", "application/json")
+
+	resp, err := p.http.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+
+		var apiErr anthropicError
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Error.Message != "" {
+			return nil, fmt.Errorf("anthropic %s: %s", apiErr.Error.Type, apiErr.Error.Message)
+		}
+		return nil, fmt.Errorf("anthropic request failed: %s", resp.Status)
+	}
+
+	return resp, nil
+}
+
+func (p *anthropicProvider) Chat(ctx context.Context, req ChatRequest) (ChatResponse, error) {
+	if len(req.Tools) > 0 {
+		return ChatResponse{}, errToolsUnsupported
+	}
+	resp, err := p.do(ctx, http.MethodPost, "/messages", p.buildRequest(req, false))
+	if err != nil {
+		return ChatResponse{}, err
+	}
+	defer resp.Body.Close()
+
+	var out anthropicResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return ChatResponse{}, fmt.Errorf("failed to decode anthropic response: %w", err)
+	}
+
+	var content strings.Builder
+	if req.JSON {
+		content.WriteString(jsonPrefill)
+	}
+	for _, block := range out.Content {
+		if block.Type == "text" {
+			content.WriteString(block.Text)
+		}
+	}
+
+	if content.Len() == 0 || (req.JSON && content.String() == jsonPrefill) {
+		return ChatResponse{}, fmt.Errorf("no response from AI")
+	}
+
+	return ChatResponse{
+		Content: content.String(),
+		Usage: Usage{
+			PromptTokens:     out.Usage.InputTokens,
+			CompletionTokens: out.Usage.OutputTokens,
+			TotalTokens:      out.Usage.InputTokens + out.Usage.OutputTokens,
+		},
+	}, nil
+}
+
+// anthropicEvent covers the server-sent event payloads we care about
+type anthropicEvent struct {
+	Type  string `json:"type"`
+	Delta struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"delta"`
+	Message struct {
+		Usage anthropicUsage `json:"usage"`
+	} `json:"message"`
+	Usage anthropicUsage `json:"usage"`
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (p *anthropicProvider) ChatStream(
+	ctx context.Context,
+	req ChatRequest,
+	onToken func(string),
+) (ChatResponse, error) {
+	if len(req.Tools) > 0 {
+		return ChatResponse{}, errToolsUnsupported
+	}
+	resp, err := p.do(ctx, http.MethodPost, "/messages", p.buildRequest(req, true))
+	if err != nil {
+		return ChatResponse{}, err
+	}
+	defer resp.Body.Close()
+
+	var content strings.Builder
+	var usage Usage
+
+	if req.JSON {
+		content.WriteString(jsonPrefill)
+		onToken(jsonPrefill)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+
+		var event anthropicEvent
+		if err := json.Unmarshal([]byte(strings.TrimSpace(strings.TrimPrefix(line, "data:"))), &event); err != nil {
+			continue
+		}
+
+		switch event.Type {
+		case "message_start":
+			usage.PromptTokens = event.Message.Usage.InputTokens
+		case "content_block_delta":
+			if event.Delta.Type == "text_delta" && event.Delta.Text != "" {
+				content.WriteString(event.Delta.Text)
+				onToken(event.Delta.Text)
+			}
+		case "message_delta":
+			usage.CompletionTokens = event.Usage.OutputTokens
+		case "error":
+			return ChatResponse{Content: content.String()},
+				fmt.Errorf("anthropic %s: %s", event.Error.Type, event.Error.Message)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return ChatResponse{Content: content.String()}, err
+	}
+
+	usage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+	return ChatResponse{Content: content.String(), Usage: usage}, nil
+}
+
+func (p *anthropicProvider) ListModels(ctx context.Context) ([]string, error) {
+	resp, err := p.do(ctx, http.MethodGet, "/models", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var out struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("failed to decode model list: %w", err)
+	}
+
+	models := make([]string, 0, len(out.Data))
+	for _, m := range out.Data {
+		models = append(models, m.ID)
+	}
+	return models, nil
+}
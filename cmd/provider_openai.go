@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// openAIProvider talks to OpenAI and any server exposing the OpenAI chat API
+// (Gemini's compatibility endpoint, Ollama, llama.cpp, vLLM, ...)
+type openAIProvider struct {
+	name     string
+	client   *openai.Client
+	jsonMode bool
+}
+
+func newOpenAIProvider(name, key, url string, jsonMode bool) *openAIProvider {
+	config := openai.DefaultConfig(key)
+	config.BaseURL = strings.TrimSuffix(url, "/")
+
+	return &openAIProvider{
+		name:     name,
+		client:   openai.NewClientWithConfig(config),
+		jsonMode: jsonMode,
+	}
+}
+
+func (p *openAIProvider) Name() string {
+	return p.name
+}
+
+func (p *openAIProvider) SupportsTools() bool {
+	return true
+}
+
+func (p *openAIProvider) request(req ChatRequest) openai.ChatCompletionRequest {
+	messages := make([]openai.ChatCompletionMessage, len(req.Messages))
+	for i, msg := range req.Messages {
+		messages[i] = openai.ChatCompletionMessage{
+			Role:       msg.Role,
+			Content:    msg.Content,
+			ToolCallID: msg.ToolCallID,
+		}
+		for _, call := range msg.ToolCalls {
+			messages[i].ToolCalls = append(messages[i].ToolCalls, openai.ToolCall{
+				ID:       call.ID,
+				Type:     openai.ToolTypeFunction,
+				Function: openai.FunctionCall{Name: call.Name, Arguments: call.Arguments},
+			})
+		}
+	}
+
+	out := openai.ChatCompletionRequest{
+		Model:       req.Model,
+		Messages:    messages,
+		Temperature: float32(req.Temperature),
+		MaxTokens:   req.MaxTokens,
+	}
+	for _, tool := range req.Tools {
+		out.Tools = append(out.Tools, openai.Tool{
+			Type: openai.ToolTypeFunction,
+			Function: &openai.FunctionDefinition{
+				Name:        tool.Name,
+				Description: tool.Description,
+				Parameters:  tool.Parameters,
+			},
+		})
+	}
+	if req.JSON && p.jsonMode {
+		out.ResponseFormat = &openai.ChatCompletionResponseFormat{
+			Type: openai.ChatCompletionResponseFormatTypeJSONObject,
+		}
+	}
+	return out
+}
+
+func (p *openAIProvider) Chat(ctx context.Context, req ChatRequest) (ChatResponse, error) {
+	resp, err := p.client.CreateChatCompletion(ctx, p.request(req))
+	if err != nil {
+		return ChatResponse{}, err
+	}
+
+	if len(resp.Choices) == 0 {
+		return ChatResponse{}, fmt.Errorf("no response from AI")
+	}
+
+	var calls []ToolCall
+	for _, call := range resp.Choices[0].Message.ToolCalls {
+		calls = append(calls, ToolCall{ID: call.ID, Name: call.Function.Name, Arguments: call.Function.Arguments})
+	}
+
+	return ChatResponse{
+		Content:   resp.Choices[0].Message.Content,
+		ToolCalls: calls,
+		Usage: Usage{
+			PromptTokens:     resp.Usage.PromptTokens,
+			CompletionTokens: resp.Usage.CompletionTokens,
+			TotalTokens:      resp.Usage.TotalTokens,
+		},
+	}, nil
+}
+
+func (p *openAIProvider) ChatStream(
+	ctx context.Context,
+	req ChatRequest,
+	onToken func(string),
+) (ChatResponse, error) {
+	request := p.request(req)
+	// The usage arrives in a final chunk without choices
+	request.StreamOptions = &openai.StreamOptions{IncludeUsage: true}
+
+	stream, err := p.client.CreateChatCompletionStream(ctx, request)
+	if err != nil {
+		return ChatResponse{}, err
+	}
+	defer stream.Close()
+
+	var content strings.Builder
+	var calls []ToolCall
+	var usage Usage
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return ChatResponse{Content: content.String()}, err
+		}
+
+		if chunk.Usage != nil {
+			usage = Usage{
+				PromptTokens:     chunk.Usage.PromptTokens,
+				CompletionTokens: chunk.Usage.CompletionTokens,
+				TotalTokens:      chunk.Usage.TotalTokens,
+			}
+		}
+
+		for _, choice := range chunk.Choices {
+			// Tool calls arrive in fragments keyed by index; servers that
+			// omit the index send each call whole
+			for _, delta := range choice.Delta.ToolCalls {
+				i := len(calls)
+				if delta.Index != nil {
+					i = *delta.Index
+				}
+				for len(calls) <= i {
+					calls = append(calls, ToolCall{})
+				}
+				if delta.ID != "" {
+					calls[i].ID = delta.ID
+				}
+				calls[i].Name += delta.Function.Name
+				calls[i].Arguments += delta.Function.Arguments
+			}
+
+			if choice.Delta.Content == "" {
+				continue
+			}
+			content.WriteString(choice.Delta.Content)
+			onToken(choice.Delta.Content)
+		}
+	}
+
+	return ChatResponse{Content: content.String(), ToolCalls: calls, Usage: usage}, nil
+}
+
+func (p *openAIProvider) ListModels(ctx context.Context) ([]string, error) {
+	list, err := p.client.ListModels(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	models := make([]string, 0, len(list.Models))
+	for _, m := range list.Models {
+		models = append(models, m.ID)
+	}
+	return models, nil
+}
@@ -0,0 +1,625 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/chzyer/readline"
+	"github.com/fatih/color"
+)
+
+// errExitREPL is returned by a slash command to end the session
+var errExitREPL = errors.New("exit repl")
+
+// slashCommand is a "/name args" command available inside the REPL
+type slashCommand struct {
+	name        string
+	aliases     []string
+	args        string
+	description string
+	complete    func() []string
+	run         func(r *repl, args string) error
+}
+
+// inputHandler gets a chance to consume input before it is sent to the AI.
+// It returns true when the input was handled.
+type inputHandler func(r *repl, input string) bool
+
+// repl is the read-eval-print loop shared by chat and interactive mode
+type repl struct {
+	provider Provider
+	messages []Message
+	session  *Session
+	saved    bool
+
+	prompt    string
+	goodbye   string
+	echoInput bool
+
+	commands []*slashCommand
+	handlers []inputHandler
+
+	lastUsage Usage
+
+	// summary stands in for the messages before summaryEnd, except the
+	// system prompt, in requests once the history was summarized to fit the
+	// context budget
+	summary    *Message
+	summaryEnd int
+	// summarizeFailed stops retrying summarization after it failed once
+	summarizeFailed bool
+
+	// agent runs tool calls in agent mode; nil for plain chat
+	agent *agent
+
+	// attachments are sent with the next message
+	attachments []Attachment
+}
+
+// newREPL creates a REPL with the built-in slash commands registered
+func newREPL(provider Provider, prompt string) *repl {
+	r := &repl{
+		provider: provider,
+		prompt:   prompt,
+		goodbye:  "👋 Goodbye!",
+		session:  newSession(),
+	}
+	r.resetMessages()
+	r.registerBuiltins()
+	return r
+}
+
+// register adds a slash command, replacing any existing one with the same name
+func (r *repl) register(cmd *slashCommand) {
+	for i, existing := range r.commands {
+		if existing.name == cmd.name {
+			r.commands[i] = cmd
+			return
+		}
+	}
+	r.commands = append(r.commands, cmd)
+}
+
+// handle adds an input handler consulted before chat messages are sent
+func (r *repl) handle(h inputHandler) {
+	r.handlers = append(r.handlers, h)
+}
+
+func (r *repl) lookup(name string) *slashCommand {
+	for _, cmd := range r.commands {
+		if cmd.name == name {
+			return cmd
+		}
+		for _, alias := range cmd.aliases {
+			if alias == name {
+				return cmd
+			}
+		}
+	}
+	return nil
+}
+
+// resetMessages starts a fresh history containing only the system prompt
+func (r *repl) resetMessages() {
+	r.messages = []Message{
+		{
+			Role:    RoleSystem,
+			Content: systemPrompt,
+		},
+	}
+	r.summary = nil
+	r.addAgentPrompt()
+}
+
+// addAgentPrompt adds the agent instructions to the system message in agent
+// mode. Sessions saved in agent mode already have them.
+func (r *repl) addAgentPrompt() {
+	if r.agent == nil {
+		return
+	}
+	if len(r.messages) == 0 || r.messages[0].Role != RoleSystem {
+		r.messages = append([]Message{{Role: RoleSystem}}, r.messages...)
+		// The summary's position in the history has moved
+		r.summary = nil
+	}
+	if !strings.Contains(r.messages[0].Content, r.agent.prompt) {
+		r.messages[0].Content = strings.TrimSpace(r.messages[0].Content + "\n\n" + r.agent.prompt)
+	}
+}
+
+// loadSession replaces the current conversation with a saved session
+func (r *repl) loadSession(s *Session) {
+	r.session = s
+	r.messages = s.Messages
+	r.summary = nil
+	r.saved = true
+	r.addAgentPrompt()
+}
+
+// completer offers slash command names and their arguments on Tab
+func (r *repl) completer() readline.AutoCompleter {
+	items := make([]readline.PrefixCompleterInterface, 0, len(r.commands))
+	for _, cmd := range r.commands {
+		var children []readline.PrefixCompleterInterface
+		if cmd.complete != nil {
+			complete := cmd.complete
+			children = append(children, readline.PcItemDynamic(func(string) []string {
+				return complete()
+			}))
+		}
+
+		items = append(items, readline.PcItem("/"+cmd.name, children...))
+		for _, alias := range cmd.aliases {
+			items = append(items, readline.PcItem("/"+alias, children...))
+		}
+	}
+	return readline.NewPrefixCompleter(items...)
+}
+
+// historyFile returns the path of the persistent input history
+func historyFile() string {
+	dir, err := configDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "history")
+}
+
+// run reads input until the user exits
+func (r *repl) run() error {
+	history := historyFile()
+	if history != "" {
+		os.MkdirAll(filepath.Dir(history), 0o755)
+	}
+
+	rl, err := readline.NewEx(&readline.Config{
+		Prompt:       r.prompt,
+		HistoryFile:  history,
+		AutoComplete: r.completer(),
+	})
+	if err != nil {
+		return fmt.Errorf("error initializing readline: %w", err)
+	}
+	defer rl.Close()
+
+	for {
+		line, err := rl.Readline()
+		if err != nil {
+			break
+		}
+
+		input := strings.TrimSpace(line)
+		if input == "" {
+			continue
+		}
+
+		if strings.HasPrefix(input, "/") {
+			if r.dispatch(input) {
+				break
+			}
+			continue
+		}
+
+		handled := false
+		for _, h := range r.handlers {
+			if h(r, input) {
+				handled = true
+				break
+			}
+		}
+		if handled {
+			continue
+		}
+
+		r.send(input)
+	}
+	return nil
+}
+
+// dispatch runs a slash command and reports whether the REPL should exit
+func (r *repl) dispatch(input string) bool {
+	name, args, _ := strings.Cut(strings.TrimPrefix(input, "/"), " ")
+	args = strings.TrimSpace(args)
+
+	cmd := r.lookup(name)
+	if cmd == nil {
+		color.Red("Unknown command /%s. Type /help to see available commands.", name)
+		return false
+	}
+
+	err := cmd.run(r, args)
+	if errors.Is(err, errExitREPL) {
+		color.New(color.FgGreen, color.Bold).Printf("\n%s\n", r.goodbye)
+		return true
+	}
+	if err != nil {
+		color.Red("Error: %v", err)
+	}
+	return false
+}
+
+// send adds a user message to the history and streams the AI's reply
+func (r *repl) send(input string) {
+	if r.echoInput {
+		color.New(color.FgMagenta, color.Bold).Printf("\nYou: %s\n", input)
+	}
+
+	r.messages = append(r.messages, Message{
+		Role:    RoleUser,
+		Content: withAttachments(input, r.attachments),
+	})
+	if r.agent != nil {
+		r.agent.task = input
+	}
+
+	if err := r.complete(); err != nil {
+		// Remove the last user message if there was an error; the
+		// attachments stay for the next attempt
+		r.messages = r.messages[:len(r.messages)-1]
+		return
+	}
+	r.attachments = nil
+}
+
+// complete asks the AI to answer the current history and records the reply.
+// In agent mode the model may call tools first; their results are added to the
+// history and the model asked again, up to maxAgentRounds times.
+func (r *repl) complete() error {
+	var tools []Tool
+	if r.agent != nil {
+		tools = r.agent.tools()
+	}
+
+	for round := 0; ; round++ {
+		messages := r.fitContext()
+
+		if r.echoInput || round > 0 {
+			fmt.Print("AI> ")
+		} else {
+			fmt.Print("\nAI> ")
+		}
+
+		response, err := getAIResponse(r.provider, messages, tools)
+		if err != nil {
+			if errors.Is(err, errResponseCancelled) {
+				color.Yellow("\n⏹️  Response cancelled")
+			} else {
+				color.Red("Error: %v\n", err)
+			}
+			if round > 0 {
+				// Keep the tool calls made so far; the user can /retry
+				r.autosave()
+				return nil
+			}
+			return err
+		}
+
+		// Add assistant response to history
+		r.messages = append(r.messages, Message{
+			Role:      RoleAssistant,
+			Content:   response.Content,
+			ToolCalls: response.ToolCalls,
+		})
+		r.lastUsage = response.Usage
+
+		if len(response.ToolCalls) == 0 || r.agent == nil {
+			fmt.Println()
+			fmt.Println()
+			r.autosave()
+			return nil
+		}
+
+		// Every call needs a result, even the ones that are not run
+		limit := round+1 >= maxAgentRounds
+		for _, call := range response.ToolCalls {
+			result := fmt.Sprintf("Not run: the limit of %d tool rounds per message was reached.", maxAgentRounds)
+			if !limit {
+				result = r.agent.runCall(call)
+			}
+			r.messages = append(r.messages, Message{
+				Role:       RoleTool,
+				Content:    result,
+				ToolCallID: call.ID,
+			})
+		}
+		fmt.Println()
+		r.autosave()
+
+		if limit {
+			color.Yellow("⚠️  Stopped after %d tool rounds. Send a message to let the AI continue.\n", maxAgentRounds)
+			return nil
+		}
+	}
+}
+
+// autosave keeps sessions the user chose to save up to date
+func (r *repl) autosave() {
+	if !r.saved {
+		return
+	}
+
+	r.session.Messages = r.messages
+	if err := saveSession(r.session); err != nil {
+		color.Red("Error saving session: %v", err)
+	}
+}
+
+func (r *repl) registerBuiltins() {
+	r.register(&slashCommand{
+		name:        "help",
+		description: "Show available commands",
+		run: func(r *repl, args string) error {
+			r.printHelp()
+			return nil
+		},
+	})
+
+	r.register(&slashCommand{
+		name:        "exit",
+		aliases:     []string{"quit"},
+		description: "Exit the session",
+		run: func(r *repl, args string) error {
+			return errExitREPL
+		},
+	})
+
+	r.register(&slashCommand{
+		name:        "clear",
+		description: "Clear conversation history",
+		run: func(r *repl, args string) error {
+			r.resetMessages()
+			r.session, r.saved = newSession(), false
+			r.attachments = nil
+			color.Green("✓ Conversation history cleared")
+			return nil
+		},
+	})
+
+	r.register(&slashCommand{
+		name:        "model",
+		args:        "[name]",
+		description: "Show or switch the AI model",
+		run: func(r *repl, args string) error {
+			if args == "" {
+				fmt.Printf("Model: %s (provider: %s)\n", model, r.provider.Name())
+				return nil
+			}
+			model = args
+			r.session.Model = args
+			color.Green("✓ Model set to %s", model)
+			return nil
+		},
+	})
+
+	r.register(&slashCommand{
+		name:        "system",
+		args:        "[prompt]",
+		description: "Show or replace the system prompt",
+		run: func(r *repl, args string) error {
+			if args == "" {
+				fmt.Printf("System prompt: %s\n", systemPrompt)
+				return nil
+			}
+			systemPrompt = args
+			if len(r.messages) > 0 && r.messages[0].Role == RoleSystem {
+				r.messages[0].Content = args
+			} else {
+				r.messages = append([]Message{{Role: RoleSystem, Content: args}}, r.messages...)
+				// The summary's position in the history has moved
+				r.summary = nil
+			}
+			r.addAgentPrompt()
+			color.Green("✓ System prompt updated")
+			return nil
+		},
+	})
+
+	r.register(&slashCommand{
+		name:        "temperature",
+		args:        "[0.0-2.0]",
+		description: "Show or set the response temperature",
+		run: func(r *repl, args string) error {
+			if args == "" {
+				fmt.Printf("Temperature: %g\n", temperature)
+				return nil
+			}
+			t, err := strconv.ParseFloat(args, 64)
+			if err != nil || t < 0 || t > 2 {
+				return fmt.Errorf("temperature must be a number between 0.0 and 2.0")
+			}
+			temperature = t
+			color.Green("✓ Temperature set to %g", temperature)
+			return nil
+		},
+	})
+
+	r.register(&slashCommand{
+		name:        "history",
+		description: "Show the conversation so far",
+		run: func(r *repl, args string) error {
+			r.session.Messages = r.messages
+			printSessionHistory(r.session)
+			return nil
+		},
+	})
+
+	r.register(&slashCommand{
+		name:        "undo",
+		description: "Remove the last exchange from the history",
+		run: func(r *repl, args string) error {
+			last := -1
+			for i := len(r.messages) - 1; i >= 0; i-- {
+				if r.messages[i].Role == RoleUser {
+					last = i
+					break
+				}
+			}
+			if last < 0 {
+				return fmt.Errorf("nothing to undo")
+			}
+
+			r.messages = r.messages[:last]
+			r.autosave()
+			color.Green("✓ Removed the last exchange")
+			return nil
+		},
+	})
+
+	r.register(&slashCommand{
+		name:        "retry",
+		description: "Regenerate the last AI response",
+		run: func(r *repl, args string) error {
+			// Drop the reply, including any tool calls made for it
+			for n := len(r.messages); n > 0 && (r.messages[n-1].Role == RoleAssistant || r.messages[n-1].Role == RoleTool); n-- {
+				r.messages = r.messages[:n-1]
+			}
+			if len(r.messages) == 0 || r.messages[len(r.messages)-1].Role != RoleUser {
+				return fmt.Errorf("nothing to retry")
+			}
+
+			// Errors are already reported by complete and the message is kept
+			// so the user can retry again
+			r.complete()
+			return nil
+		},
+	})
+
+	r.register(&slashCommand{
+		name:        "attach",
+		args:        "[path]",
+		description: "Attach a file to your next message, or list attached files",
+		complete:    listFiles,
+		run: func(r *repl, args string) error {
+			if args == "" {
+				if len(r.attachments) == 0 {
+					fmt.Println("No files attached")
+				}
+				for _, a := range r.attachments {
+					fmt.Printf("📎 %s\n", a.Summary())
+				}
+				return nil
+			}
+
+			remaining := maxAttachmentsSize
+			for _, a := range r.attachments {
+				remaining -= len(a.Content)
+			}
+			if remaining <= 0 {
+				return fmt.Errorf("the attachments already reach the %s limit per message", formatBytes(maxAttachmentsSize))
+			}
+
+			a, err := attachFile(args, min(maxAttachmentSize, remaining))
+			if err != nil {
+				return err
+			}
+			r.attachments = append(r.attachments, a)
+			color.Green("📎 Attached %s; it is sent with your next message", a.Summary())
+			return nil
+		},
+	})
+
+	r.register(&slashCommand{
+		name:        "tokens",
+		description: "Show token usage against the context budget",
+		run: func(r *repl, args string) error {
+			r.printTokenUsage()
+			return nil
+		},
+	})
+
+	r.register(&slashCommand{
+		name:        "save",
+		args:        "[name]",
+		description: "Save the conversation (kept up to date afterwards)",
+		run: func(r *repl, args string) error {
+			if args != "" {
+				r.session.Name = args
+			}
+			r.session.Messages = r.messages
+			if err := saveSession(r.session); err != nil {
+				return fmt.Errorf("saving session: %w", err)
+			}
+			r.saved = true
+			color.Green("✓ Session saved as %s (%s)", r.session.ID, r.session.Title())
+			return nil
+		},
+	})
+
+	r.register(&slashCommand{
+		name:        "load",
+		args:        "<session>",
+		description: "Load a saved session by ID or name",
+		complete:    sessionRefs,
+		run: func(r *repl, args string) error {
+			if args == "" {
+				return fmt.Errorf("usage: /load <session>")
+			}
+			loaded, err := findSession(args)
+			if err != nil {
+				return err
+			}
+			r.loadSession(loaded)
+			printSessionHistory(loaded)
+			color.Green("✓ Loaded session %s", loaded.ID)
+			return nil
+		},
+	})
+}
+
+// printHelp lists the registered slash commands
+func (r *repl) printHelp() {
+	yellow := color.New(color.FgYellow)
+
+	commands := make([]*slashCommand, len(r.commands))
+	copy(commands, r.commands)
+	sort.Slice(commands, func(i, j int) bool { return commands[i].name < commands[j].name })
+
+	fmt.Println("\nCommands:")
+	for _, cmd := range commands {
+		usage := "/" + cmd.name
+		if cmd.args != "" {
+			usage += " " + cmd.args
+		}
+		yellow.Printf("  %-24s", usage)
+		fmt.Printf(" %s\n", cmd.description)
+	}
+	fmt.Println()
+}
+
+// sessionRefs lists saved session IDs and names for completion
+func sessionRefs() []string {
+	sessions, err := listSessions()
+	if err != nil {
+		return nil
+	}
+
+	var refs []string
+	for _, s := range sessions {
+		refs = append(refs, s.ID)
+		if s.Name != "" {
+			refs = append(refs, s.Name)
+		}
+	}
+	return refs
+}
+
+// listFiles completes /attach with the files in the working directory
+func listFiles() []string {
+	entries, err := os.ReadDir(".")
+	if err != nil {
+		return nil
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if entry.Type().IsRegular() {
+			files = append(files, entry.Name())
+		}
+	}
+	return files
+}
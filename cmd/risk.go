@@ -0,0 +1,273 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// RiskLevel grades how dangerous a command is
+type RiskLevel int
+
+const (
+	RiskLow RiskLevel = iota
+	RiskMedium
+	RiskHigh
+	RiskCritical
+)
+
+func (l RiskLevel) String() string {
+	switch l {
+	case RiskMedium:
+		return "medium"
+	case RiskHigh:
+		return "high"
+	case RiskCritical:
+		return "critical"
+	default:
+		return "low"
+	}
+}
+
+// RiskFinding is one reason a command was flagged
+type RiskFinding struct {
+	Level  RiskLevel
+	Reason string
+}
+
+// RiskAssessment is the result of classifying a command
+type RiskAssessment struct {
+	Level    RiskLevel
+	Findings []RiskFinding
+}
+
+// riskRule flags commands matching a pattern
+type riskRule struct {
+	level   RiskLevel
+	pattern *regexp.Regexp
+	reason  string
+}
+
+// Pieces of the rm patterns. Options may appear anywhere among the arguments
+// of the same command, so rm's arguments are matched up to the next ; & or |.
+const (
+	rmArgs        = `([^;&|\n]*\s)?`
+	rmRecursive   = `(-[a-zA-Z]*[rR][a-zA-Z]*|--recursive)`
+	rmBroadTarget = `"?(/|/\*|~|~/|\$HOME|\$\{HOME\}|\*|\.|\./|\./\*|\.\.|\.\./)"?`
+	argEnd        = `(\s|$|;|&|\|)`
+)
+
+var riskRules = []riskRule{
+	{RiskCritical, regexp.MustCompile(`\brm\s+` + rmArgs + `(` + rmRecursive + `\s+` + rmArgs + rmBroadTarget + `|` +
+		rmBroadTarget + `\s+` + rmArgs + rmRecursive + `)` + argEnd),
+		"recursively deletes the root, home, current or parent directory"},
+	{RiskCritical, regexp.MustCompile(`\bdd\b.*\bof=/dev/`), "writes raw data to a device with dd"},
+	{RiskCritical, regexp.MustCompile(`\bmkfs(\.\w+)?\b`), "formats a filesystem"},
+	{RiskCritical, regexp.MustCompile(`>\s*/dev/(sd|nvme|hd|vd|disk)`), "overwrites a block device"},
+	{RiskCritical, regexp.MustCompile(`:\(\)\s*\{\s*:\s*\|\s*:\s*&\s*\}\s*;\s*:`), "fork bomb"},
+	{RiskCritical, regexp.MustCompile(`\bchmod\s+(-[a-zA-Z]*R[a-zA-Z]*\s+)\S*\s+/(\s|$)`), "recursively changes permissions on /"},
+
+	{RiskHigh, regexp.MustCompile(`\b(curl|wget)\b[^|]*\|\s*(sudo\s+)?(-\S+\s+)*(sh|bash|zsh|dash|ksh|fish|python3?|perl)\b`),
+		"pipes a downloaded script straight into an interpreter"},
+	// bash -c "$(curl ...)", sh <(curl ...), . <(wget -O- ...), eval "$(curl ...)"
+	{RiskHigh, regexp.MustCompile(`(\b(sh|bash|zsh|dash|ksh|fish|python3?|perl|source|eval)|(^|[;&|(]\s*)\.)\s+(-\S+\s+)*["']?(\$\(|<\()\s*(sudo\s+)?(curl|wget)\b`),
+		"runs a downloaded script straight in an interpreter"},
+	{RiskHigh, regexp.MustCompile(`\brm\s+` + rmArgs + rmRecursive + argEnd), "removes files recursively"},
+	{RiskHigh, regexp.MustCompile(`\bchmod\s+(-\S+\s+)*0?777\b`), "makes files world-writable (chmod 777)"},
+	{RiskHigh, regexp.MustCompile(`(>>?|\btee\s+(-a\s+)?|\bsed\s+-i\S*\s+.*\s|\b(cp|mv|ln)\s+.*\s)/etc/`), "modifies system configuration under /etc"},
+	{RiskHigh, regexp.MustCompile(`\b(shutdown|reboot|halt|poweroff)\b`), "shuts down or restarts the machine"},
+	{RiskHigh, regexp.MustCompile(`\biptables\s+(-\S+\s+)*-F\b|\bufw\s+disable\b`), "disables the firewall"},
+	{RiskHigh, regexp.MustCompile(`\bchown\s+(-\S+\s+)*-R\b.*\s/(usr|etc|bin|lib|var)?(\s|$)`), "recursively changes ownership of system directories"},
+
+	{RiskMedium, regexp.MustCompile(`(^|[;&|]\s*)sudo\b`), "runs with administrator privileges (sudo)"},
+	{RiskMedium, regexp.MustCompile(`\beval\b`), "evaluates dynamically built code"},
+	{RiskMedium, regexp.MustCompile(`\b(apt|apt-get|dnf|yum|pacman|brew|snap)\s+(-\S+\s+)*(remove|purge|autoremove|erase|uninstall|-R\w*)\b`),
+		"removes installed packages"},
+	{RiskMedium, regexp.MustCompile(`\bsystemctl\s+(stop|disable|mask)\b`), "stops or disables a system service"},
+	{RiskMedium, regexp.MustCompile(`\b(usermod|useradd|userdel|passwd|visudo)\b`), "changes user accounts or privileges"},
+}
+
+// trustedSources are official download sources that do not raise the risk
+// level. A plain host also covers its subdomains. Hosts that serve anyone's
+// content, such as GitHub, are only trusted for the listed path prefixes.
+var trustedSources = []string{
+	"github.com/cli/", "github.com/docker/", "github.com/kubernetes/", "github.com/nvm-sh/nvm/",
+	"github.com/rust-lang/", "github.com/golang/", "github.com/nodejs/", "github.com/Homebrew/",
+	"raw.githubusercontent.com/nvm-sh/nvm/", "raw.githubusercontent.com/Homebrew/install/",
+	"raw.githubusercontent.com/docker/", "raw.githubusercontent.com/kubernetes/",
+	"sh.rustup.rs", "static.rust-lang.org", "rust-lang.org",
+	"get.docker.com", "download.docker.com", "docker.com",
+	"nodejs.org", "deb.nodesource.com", "rpm.nodesource.com", "registry.npmjs.org", "npmjs.org",
+	"go.dev", "golang.org", "dl.google.com",
+	"pypi.org", "files.pythonhosted.org", "bootstrap.pypa.io", "python.org",
+	"packages.microsoft.com", "code.visualstudio.com", "update.code.visualstudio.com", "aka.ms",
+	"brew.sh", "deno.land", "bun.sh", "get.sdkman.io",
+	"releases.hashicorp.com", "apt.releases.hashicorp.com",
+	"cli.github.com", "download.jetbrains.com", "dl.k8s.io", "pkgs.k8s.io",
+}
+
+var urlPattern = regexp.MustCompile(`https?://[^\s'"|;&)]+`)
+
+// classifyCommand statically analyzes a command and grades its risk
+func classifyCommand(command string) RiskAssessment {
+	var assessment RiskAssessment
+
+	add := func(level RiskLevel, reason string) {
+		assessment.Findings = append(assessment.Findings, RiskFinding{Level: level, Reason: reason})
+		if level > assessment.Level {
+			assessment.Level = level
+		}
+	}
+
+	for _, rule := range riskRules {
+		if rule.pattern.MatchString(command) {
+			add(rule.level, rule.reason)
+		}
+	}
+
+	for _, raw := range urlPattern.FindAllString(command, -1) {
+		u, err := url.Parse(raw)
+		if err != nil || u.Hostname() == "" {
+			continue
+		}
+		if !isTrustedSource(u) {
+			add(RiskMedium, fmt.Sprintf("downloads from a non-official source (%s)", u.Hostname()))
+		}
+		if u.Scheme == "http" {
+			add(RiskMedium, fmt.Sprintf("downloads over unencrypted HTTP (%s)", u.Hostname()))
+		}
+	}
+
+	return assessment
+}
+
+// isTrustedSource reports whether u is on a trusted host, or one of its
+// subdomains, or under a trusted path prefix
+func isTrustedSource(u *url.URL) bool {
+	host := strings.ToLower(u.Hostname())
+	for _, trusted := range trustedSources {
+		trustedHost, prefix, hasPath := strings.Cut(trusted, "/")
+		if hasPath {
+			// GitHub owner and repository names are case-insensitive
+			path := strings.ToLower(strings.TrimPrefix(u.Path, "/"))
+			if host == trustedHost && strings.HasPrefix(path, strings.ToLower(prefix)) {
+				return true
+			}
+			continue
+		}
+		if host == trusted || strings.HasSuffix(host, "."+trusted) {
+			return true
+		}
+	}
+	return false
+}
+
+// RiskAction is what the safety policy does with a command of a given risk
+type RiskAction string
+
+const (
+	RiskAllow   RiskAction = "allow"
+	RiskConfirm RiskAction = "confirm"
+	RiskBlock   RiskAction = "block"
+)
+
+// defaultRiskPolicy is used for any level the user does not configure
+var defaultRiskPolicy = map[RiskLevel]RiskAction{
+	RiskLow:      RiskAllow,
+	RiskMedium:   RiskAllow,
+	RiskHigh:     RiskConfirm,
+	RiskCritical: RiskBlock,
+}
+
+// parseRiskPolicy parses "high=confirm,critical=block" on top of the defaults
+func parseRiskPolicy(spec string) (map[RiskLevel]RiskAction, error) {
+	policy := map[RiskLevel]RiskAction{}
+	for level, action := range defaultRiskPolicy {
+		policy[level] = action
+	}
+
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		name, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid risk policy entry %q (expected level=action)", part)
+		}
+
+		level, err := parseRiskLevel(strings.TrimSpace(name))
+		if err != nil {
+			return nil, err
+		}
+
+		action := RiskAction(strings.ToLower(strings.TrimSpace(value)))
+		if action != RiskAllow && action != RiskConfirm && action != RiskBlock {
+			return nil, fmt.Errorf("invalid risk action %q (use allow, confirm or block)", value)
+		}
+		policy[level] = action
+	}
+	return policy, nil
+}
+
+func parseRiskLevel(name string) (RiskLevel, error) {
+	for _, level := range []RiskLevel{RiskLow, RiskMedium, RiskHigh, RiskCritical} {
+		if strings.EqualFold(name, level.String()) {
+			return level, nil
+		}
+	}
+	return RiskLow, fmt.Errorf("invalid risk level %q (use low, medium, high or critical)", name)
+}
+
+// riskColor returns the display color for a risk level
+func riskColor(level RiskLevel) *color.Color {
+	switch level {
+	case RiskCritical:
+		return color.New(color.FgHiRed, color.Bold, color.ReverseVideo)
+	case RiskHigh:
+		return color.New(color.FgRed, color.Bold)
+	case RiskMedium:
+		return color.New(color.FgYellow, color.Bold)
+	default:
+		return color.New(color.FgGreen)
+	}
+}
+
+// printRiskAssessment shows why a command was flagged, if it was
+func printRiskAssessment(assessment RiskAssessment, action RiskAction, indent string) {
+	if assessment.Level == RiskLow {
+		return
+	}
+
+	c := riskColor(assessment.Level)
+	label := strings.ToUpper(assessment.Level.String()) + " RISK"
+	switch action {
+	case RiskBlock:
+		label += " · BLOCKED BY POLICY"
+	case RiskConfirm:
+		label += " · TYPED CONFIRMATION REQUIRED"
+	}
+
+	c.Printf("%s⚠️  %s\n", indent, label)
+	for _, finding := range assessment.Findings {
+		riskColor(finding.Level).Printf("%s   - %s\n", indent, finding.Reason)
+	}
+}
+
+// typedConfirmation requires the user to type a phrase before running a risky
+// command. It is asked even under --yes.
+func typedConfirmation(reader *bufio.Reader, level RiskLevel) bool {
+	phrase := "run " + level.String() + " risk"
+	riskColor(level).Printf("\n🔐 This command is %s risk. Type '%s' to execute it: ", level, phrase)
+
+	response, err := reader.ReadString('\n')
+	if err != nil && response == "" {
+		return false
+	}
+	return strings.TrimSpace(response) == phrase
+}
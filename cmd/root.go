@@ -2,15 +2,18 @@ package cmd
 
 import (
 	"fmt"
-	"os"
+	"strings"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
 
 var (
-	apiKey string
-	model  string
+	apiKey       string
+	model        string
+	providerName string
+	baseURL      string
+	noStream     bool
 )
 
 var rootCmd = &cobra.Command{
@@ -18,6 +21,20 @@ var rootCmd = &cobra.Command{
 	Short: "LiveCLI - AI-powered command-line interface",
 	Long: `LiveCLI is an intelligent CLI tool that combines system command execution 
 with AI-powered chat assistance. Execute commands, get AI help, and boost your productivity.`,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := applyConfig(cmd); err != nil {
+			return err
+		}
+		if err := configureOutput(cmd); err != nil {
+			return err
+		}
+		if model == "" {
+			model = defaultModelFor(providerName)
+		}
+		return nil
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 		displayWelcome()
 	},
@@ -30,17 +47,41 @@ func Execute() error {
 func init() {
 	// Global flags
 	rootCmd.PersistentFlags().
-		StringVar(&apiKey, "api-key", os.Getenv("OPENAI_API_KEY"), "OpenAI API key (or set OPENAI_API_KEY env var)")
-	rootCmd.PersistentFlags().StringVarP(&model, "model", "m", "gpt-4o-mini", "AI model to use")
+		StringVar(&apiKey, "api-key", "", "API key (defaults to the provider's env var, e.g. OPENAI_API_KEY)")
+	rootCmd.PersistentFlags().
+		StringVarP(&model, "model", "m", "", "AI model to use (defaults to the provider's default model)")
+	rootCmd.PersistentFlags().StringVar(
+		&providerName,
+		"provider",
+		"openai",
+		"LLM provider: "+strings.Join(providerNames(), ", "),
+	)
+	rootCmd.PersistentFlags().
+		StringVar(&baseURL, "base-url", "", "Override the provider API base URL (e.g. a local inference server)")
+	rootCmd.PersistentFlags().
+		BoolVar(&noStream, "no-stream", false, "Wait for the full response instead of streaming tokens")
+	rootCmd.PersistentFlags().
+		StringVar(&profileName, "profile", "", "Config profile to use (or set LIVECLI_PROFILE)")
+	rootCmd.PersistentFlags().
+		StringVar(&outputFormat, "output", "", "Output format: text, plain or json (default text, plain when not a terminal)")
+
+	rootCmd.PersistentFlags().StringVarP(
+		&systemPrompt,
+		"system",
+		"s",
+		"You are a helpful AI assistant specialized in programming, system administration, and command-line tools.",
+		"System prompt for the AI",
+	)
+	rootCmd.PersistentFlags().IntVarP(&maxTokens, "max-tokens", "t", 1000, "Maximum tokens in response")
+	rootCmd.PersistentFlags().
+		Float64VarP(&temperature, "temperature", "T", 0.7, "Temperature for AI responses (0.0-2.0)")
 }
 
 func displayWelcome() {
 	cyan := color.New(color.FgCyan, color.Bold)
 	yellow := color.New(color.FgYellow)
 
-	cyan.Println("\n╔═══════════════════════════════════════════════════════════╗")
-	cyan.Println("║            🚀 Welcome to LiveCLI v1.0                     ║")
-	cyan.Println("╚═══════════════════════════════════════════════════════════╝")
+	printBanner(cyan, cyan, "║            🚀 Welcome to LiveCLI v1.0                     ║")
 
 	fmt.Println("\nAvailable Commands:")
 	yellow.Println("  livecli setup <task>      - AI-powered setup assistant")
@@ -48,12 +89,19 @@ func displayWelcome() {
 	yellow.Println("  livecli chat              - Start AI chat session")
 	yellow.Println("  livecli interactive       - Interactive mode (exec + chat)")
 	yellow.Println("  livecli ask <question>    - Quick AI question")
+	yellow.Println("  livecli explain <command> - Explain a command flag by flag")
+	yellow.Println("  livecli do <request>      - Turn a sentence into a command and run it")
+	yellow.Println("  livecli fix               - Fix the last failed shell command")
+	yellow.Println("  livecli suggest <request> - Print a command for a request without running it")
+	yellow.Println("  livecli models            - List models from the selected provider")
+	yellow.Println("  livecli history           - Audit log of executed commands")
 
 	fmt.Println("\nExamples:")
 	fmt.Println("  livecli setup \"rust into my system\"")
 	fmt.Println("  livecli setup \"vscode editor\"")
 	fmt.Println("  livecli chat")
 	fmt.Println("  livecli ask \"How do I list all running processes?\"")
+	fmt.Println("  livecli do \"find all files over 100MB modified this week\"")
 	fmt.Println("  livecli interactive")
 
 	fmt.Println("\nUse 'livecli <command> --help' for more information about a command.")
@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// StepStatus describes what happened to a step during execution
+type StepStatus string
+
+const (
+	StepSucceeded StepStatus = "succeeded"
+	StepFailed    StepStatus = "failed"
+	StepSkipped   StepStatus = "skipped"
+	StepSatisfied StepStatus = "satisfied"
+)
+
+// StepResult records the outcome of running a single command
+type StepResult struct {
+	Command  string
+	Status   StepStatus
+	ExitCode int
+	Stdout   string
+	Stderr   string
+	Duration time.Duration
+	Err      error
+}
+
+// shellCommand builds an exec.Cmd that runs commandStr through the user's shell
+func shellCommand(commandStr string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.Command("cmd", "/C", commandStr)
+	}
+
+	shell := "sh"
+	if os.Getenv("SHELL") != "" {
+		shell = os.Getenv("SHELL")
+	}
+	return exec.Command(shell, "-c", commandStr)
+}
+
+// runCommand runs a shell command, streaming its output to the terminal while
+// also capturing stdout, stderr, exit code and duration
+func runCommand(commandStr string) StepResult {
+	return execute(shellCommand(commandStr), commandStr)
+}
+
+// runArgs runs a program with an explicit argument vector, bypassing the shell
+// so that arguments are never re-interpreted
+func runArgs(name string, args ...string) StepResult {
+	return execute(exec.Command(name, args...), displayArgs(name, args))
+}
+
+// execute runs cmd with its output teed to the terminal and records the result
+// under the display string
+func execute(cmd *exec.Cmd, display string) StepResult {
+	var stdout, stderr bytes.Buffer
+
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = io.MultiWriter(os.Stdout, &stdout)
+	cmd.Stderr = io.MultiWriter(os.Stderr, &stderr)
+
+	start := time.Now()
+	err := cmd.Run()
+
+	result := StepResult{
+		Command:  display,
+		Status:   StepSucceeded,
+		Stdout:   stdout.String(),
+		Stderr:   stderr.String(),
+		Duration: time.Since(start),
+		Err:      err,
+	}
+
+	if err != nil {
+		result.Status = StepFailed
+		result.ExitCode = -1
+
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			result.ExitCode = exitErr.ExitCode()
+		}
+	}
+
+	return result
+}
+
+// runQuiet runs a shell command without a terminal, capturing its combined
+// output in Stdout. Commands still running after timeout are killed.
+func runQuiet(commandStr string, timeout time.Duration) StepResult {
+	var output bytes.Buffer
+	cmd := shellCommand(commandStr)
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	// Background children keep the output pipe open after the shell is gone;
+	// stop waiting for them instead of for the whole of their lifetime
+	cmd.WaitDelay = time.Second
+
+	start := time.Now()
+	err := cmd.Start()
+	if err == nil {
+		done := make(chan error, 1)
+		go func() { done <- cmd.Wait() }()
+
+		select {
+		case err = <-done:
+		case <-time.After(timeout):
+			cmd.Process.Kill()
+			<-done
+			err = fmt.Errorf("timed out after %s", timeout)
+		}
+	}
+
+	result := StepResult{
+		Command:  commandStr,
+		Status:   StepSucceeded,
+		Stdout:   output.String(),
+		Duration: time.Since(start),
+		Err:      err,
+	}
+	if err != nil {
+		result.Status = StepFailed
+		result.ExitCode = -1
+
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			result.ExitCode = exitErr.ExitCode()
+		}
+	}
+	return result
+}
+
+// displayArgs renders an argument vector for display, quoting arguments that
+// contain whitespace or shell metacharacters. It is never executed.
+func displayArgs(name string, args []string) string {
+	parts := []string{name}
+	for _, arg := range args {
+		if arg == "" || strings.ContainsAny(arg, " \t\n'\"$`\\;&|<>()*?!#~{}[]") {
+			arg = shellQuote(arg)
+		}
+		parts = append(parts, arg)
+	}
+	return strings.Join(parts, " ")
+}
+
+// shellQuote wraps s in single quotes so a POSIX shell reads it literally
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// truncateOutput keeps the tail of long command output, where errors usually are,
+// and marks how much was dropped
+func truncateOutput(output string, limit int) string {
+	output = strings.TrimRight(output, "\n")
+	if len(output) <= limit {
+		return output
+	}
+	dropped := len(output) - limit
+	return fmt.Sprintf("[... %d bytes truncated ...]\n%s", dropped, output[dropped:])
+}
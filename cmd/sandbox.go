@@ -0,0 +1,374 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// sandboxRuntime runs a shell script, read from stdin, in a throwaway
+// environment that is discarded afterwards
+type sandboxRuntime struct {
+	name string
+	// needsImage is set for container runtimes, which need a distro image
+	needsImage bool
+	command    func(image string) *exec.Cmd
+}
+
+// sandboxRuntimes are tried in order of preference
+var sandboxRuntimes = []sandboxRuntime{
+	{
+		name:       "podman",
+		needsImage: true,
+		command: func(image string) *exec.Cmd {
+			return exec.Command("podman", "run", "--rm", "-i", image, "sh", "-s")
+		},
+	},
+	{
+		name:       "docker",
+		needsImage: true,
+		command: func(image string) *exec.Cmd {
+			return exec.Command("docker", "run", "--rm", "-i", image, "sh", "-s")
+		},
+	},
+	{
+		// Bubblewrap reuses the host root under a temporary overlay, so the
+		// distro always matches; it needs bwrap 0.9+ and user namespaces.
+		// The home directory is replaced by an empty tmpfs so steps can't
+		// read keys or tokens from it; the network stays shared for downloads.
+		name: "bwrap",
+		command: func(string) *exec.Cmd {
+			args := []string{
+				"--overlay-src", "/", "--tmp-overlay", "/",
+				"--dev", "/dev", "--proc", "/proc", "--tmpfs", "/tmp",
+			}
+			for _, dir := range sandboxHomes() {
+				args = append(args, "--tmpfs", dir)
+			}
+			args = append(args,
+				"--unshare-all", "--share-net", "--uid", "0", "--gid", "0",
+				"--die-with-parent",
+				"sh", "-s")
+			return exec.Command("bwrap", args...)
+		},
+	},
+}
+
+// sandboxHomes lists the home directories hidden from a bwrap sandbox: the
+// user's and root's, which steps run as
+func sandboxHomes() []string {
+	homes := []string{"/root"}
+	if home, err := os.UserHomeDir(); err == nil && home != "/" && home != "/root" {
+		homes = append(homes, home)
+	}
+	return homes
+}
+
+// maxSandboxOutput bounds the output shown for a step that failed in the sandbox
+const maxSandboxOutput = 1500
+
+// sudoShim stands in for sudo in images that run as root without it
+const sudoShim = `if [ "$(id -u)" = 0 ] && ! command -v sudo >/dev/null 2>&1; then
+  mkdir -p /usr/local/bin
+  cat > /usr/local/bin/sudo <<'LIVECLI_SUDO'
+#!/bin/sh
+while [ $# -gt 0 ]; do
+  case "$1" in
+    -u|-g|-p|-C|-h) shift 2 ;;
+    --) shift; break ;;
+    -*) shift ;;
+    *) break ;;
+  esac
+done
+exec "$@"
+LIVECLI_SUDO
+  chmod +x /usr/local/bin/sudo
+fi
+`
+
+// sandboxResult is what happened to one step in the sandbox
+type sandboxResult struct {
+	status   StepStatus
+	exitCode int
+	output   strings.Builder
+	duration time.Duration
+	blocked  bool
+	// declined is set when the typed confirmation the policy asks for was not given
+	declined bool
+}
+
+// selectSandboxRuntime returns the requested runtime, or the first one
+// installed when name is "auto"
+func selectSandboxRuntime(name string) (sandboxRuntime, error) {
+	var names []string
+	for _, rt := range sandboxRuntimes {
+		names = append(names, rt.name)
+		if name != "auto" && name != rt.name {
+			continue
+		}
+		if onPath(rt.name) {
+			return rt, nil
+		}
+		if name != "auto" {
+			return sandboxRuntime{}, fmt.Errorf("%s is not installed", rt.name)
+		}
+	}
+
+	if name != "auto" {
+		return sandboxRuntime{}, fmt.Errorf("unknown sandbox %q (use auto, %s)", name, strings.Join(names, ", "))
+	}
+	return sandboxRuntime{}, fmt.Errorf("no sandbox available: install %s", strings.Join(names, ", "))
+}
+
+// sandboxImage picks a container image matching the detected distribution
+func sandboxImage(info SystemInfo) (string, error) {
+	if info.OS != "linux" {
+		return "", fmt.Errorf("container sandboxes need a Linux host, this is %s", info.Name)
+	}
+
+	major, _, _ := strings.Cut(info.Version, ".")
+	withVersion := func(image, version string) string {
+		if version == "" {
+			return image + ":latest"
+		}
+		return image + ":" + version
+	}
+
+	switch info.Distro {
+	case "ubuntu", "debian", "fedora":
+		return withVersion(info.Distro, info.Version), nil
+	case "alpine":
+		parts := strings.SplitN(info.Version, ".", 3)
+		return withVersion("alpine", strings.Join(parts[:min(2, len(parts))], ".")), nil
+	case "arch", "archlinux":
+		return "archlinux:latest", nil
+	case "rocky":
+		return withVersion("rockylinux", major), nil
+	case "almalinux":
+		return withVersion("almalinux", major), nil
+	case "centos":
+		return "quay.io/centos/centos:stream" + major, nil
+	case "amzn":
+		return withVersion("amazonlinux", info.Version), nil
+	case "opensuse-leap":
+		return withVersion("opensuse/leap", info.Version), nil
+	case "opensuse-tumbleweed":
+		return "opensuse/tumbleweed:latest", nil
+	}
+
+	// Derivatives such as Linux Mint or Pop!_OS test against their base
+	for _, like := range info.DistroLike {
+		switch like {
+		case "ubuntu", "debian", "fedora":
+			return like + ":latest", nil
+		case "arch":
+			return "archlinux:latest", nil
+		case "rhel":
+			return "rockylinux:9", nil
+		}
+	}
+	return "", fmt.Errorf("no container image known for %s", info.Name)
+}
+
+// sandboxScript runs every allowed step in its own shell, framing each with
+// markers on stdout so results can be told apart
+func sandboxScript(steps []SetupStep, allowed []bool, marker string) string {
+	var b strings.Builder
+	b.WriteString("export DEBIAN_FRONTEND=noninteractive USER=\"${USER:-root}\" HOME=\"${HOME:-/root}\" PATH=\"/usr/local/bin:$PATH\"\n")
+	b.WriteString(sudoShim)
+	b.WriteString("LIVECLI_SHELL=sh\ncommand -v bash >/dev/null 2>&1 && LIVECLI_SHELL=bash\n")
+
+	for i, step := range steps {
+		if !allowed[i] {
+			continue
+		}
+		fmt.Fprintf(&b, "echo '%s start %d'\n", marker, i)
+		fmt.Fprintf(&b, "$LIVECLI_SHELL -c %s </dev/null 2>&1\n", shellQuote(step.Command))
+		fmt.Fprintf(&b, "echo \"%s end %d $?\"\n", marker, i)
+	}
+	return b.String()
+}
+
+// sandboxPlan runs the plan in a throwaway environment and reports which steps
+// succeed. The risk policy applies as on the host: blocked steps never run and
+// steps that need typed confirmation ask for it first. approval records how the
+// user agreed to the sandbox run. It returns whether every required step
+// succeeded.
+func sandboxPlan(runtimeName string, steps []SetupStep, audit AuditEntry, approval string) (bool, error) {
+	rt, err := selectSandboxRuntime(runtimeName)
+	if err != nil {
+		return false, err
+	}
+
+	image := ""
+	where := rt.name
+	if rt.needsImage {
+		if image, err = sandboxImage(probeSystem()); err != nil {
+			return false, err
+		}
+		where += " " + image
+	}
+
+	cyan := color.New(color.FgCyan, color.Bold)
+	yellow := color.New(color.FgYellow, color.Bold)
+
+	cyan.Printf("\n🧪 Testing the plan in a throwaway sandbox (%s)...\n", where)
+	if rt.needsImage {
+		yellow.Println("   The first run may take a while to pull the image.")
+	}
+
+	// Blocked steps never run, not even in the sandbox, and steps that need
+	// typed confirmation on the host need it here too
+	reader := bufio.NewReader(os.Stdin)
+	results := make([]*sandboxResult, len(steps))
+	allowed := make([]bool, len(steps))
+	approvals := make([]string, len(steps))
+	for i, step := range steps {
+		results[i] = &sandboxResult{status: StepSkipped}
+		assessment := classifyCommand(step.Command)
+		switch riskPolicy[assessment.Level] {
+		case RiskBlock:
+			results[i].blocked = true
+			continue
+		case RiskConfirm:
+			fmt.Printf("\n   Step %d/%d: %s\n", i+1, len(steps), step.Description)
+			color.New(color.FgMagenta, color.Bold).Printf("   Command: %s\n", step.Command)
+			if !typedConfirmation(reader, assessment.Level) {
+				results[i].declined = true
+				continue
+			}
+			approvals[i] = approvalTyped
+		default:
+			approvals[i] = approval
+		}
+		allowed[i] = true
+	}
+
+	marker := fmt.Sprintf("@@livecli-sandbox-%d", time.Now().UnixNano())
+	cmd := rt.command(image)
+	cmd.Stdin = strings.NewReader(sandboxScript(steps, allowed, marker))
+	cmd.Stderr = os.Stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return false, err
+	}
+	if err := cmd.Start(); err != nil {
+		return false, fmt.Errorf("starting %s: %w", rt.name, err)
+	}
+
+	var current *sandboxResult
+	var started time.Time
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		fields := strings.Fields(line)
+		if len(fields) < 3 || fields[0] != marker {
+			if current != nil {
+				current.output.WriteString(line + "\n")
+			}
+			continue
+		}
+
+		i, err := strconv.Atoi(fields[2])
+		if err != nil || i < 0 || i >= len(steps) {
+			continue
+		}
+
+		switch fields[1] {
+		case "start":
+			current, started = results[i], time.Now()
+			fmt.Printf("   ⏳ Step %d/%d: %s\n", i+1, len(steps), steps[i].Description)
+		case "end":
+			if current == nil || len(fields) < 4 {
+				continue
+			}
+			current.duration = time.Since(started)
+			current.exitCode, _ = strconv.Atoi(fields[3])
+			current.status = StepSucceeded
+			if current.exitCode != 0 {
+				current.status = StepFailed
+			}
+
+			entry := audit
+			entry.Source = "sandbox"
+			entry.Approval = approvals[i]
+			entry.Risk = classifyCommand(steps[i].Command).Level.String()
+			recordAudit(entry, StepResult{
+				Command:  steps[i].Command,
+				Status:   current.status,
+				ExitCode: current.exitCode,
+				Stdout:   current.output.String(),
+				Duration: current.duration,
+			})
+			current = nil
+		}
+	}
+
+	if err := cmd.Wait(); err != nil && scanner.Err() == nil {
+		// The script itself always exits 0, so this is the runtime failing
+		ran := false
+		for _, r := range results {
+			ran = ran || r.status != StepSkipped
+		}
+		if !ran {
+			return false, fmt.Errorf("%s failed: %w", rt.name, err)
+		}
+	}
+
+	return printSandboxReport(steps, results), nil
+}
+
+// printSandboxReport lists each step's sandbox outcome and returns whether all
+// required steps succeeded
+func printSandboxReport(steps []SetupStep, results []*sandboxResult) bool {
+	cyan := color.New(color.FgCyan, color.Bold)
+	green := color.New(color.FgGreen, color.Bold)
+	yellow := color.New(color.FgYellow, color.Bold)
+	red := color.New(color.FgRed, color.Bold)
+
+	cyan.Println("\n🧪 Sandbox Results:")
+	cyan.Println("─────────────────────────────────────────────────────────────")
+
+	ok := true
+	for i, step := range steps {
+		r := results[i]
+		switch {
+		case r.blocked:
+			red.Printf("🚫 %d. %s (blocked by risk policy)\n", i+1, step.Description)
+		case r.declined:
+			yellow.Printf("⏭ %d. %s (not confirmed)\n", i+1, step.Description)
+		case r.status == StepSucceeded:
+			green.Printf("✓ %d. %s", i+1, step.Description)
+			fmt.Printf(" (%s)\n", formatDuration(r.duration))
+		case r.status == StepFailed:
+			red.Printf("✗ %d. %s", i+1, step.Description)
+			fmt.Printf(" (exit code %d after %s)\n", r.exitCode, formatDuration(r.duration))
+			if out := truncateOutput(r.output.String(), maxSandboxOutput); out != "" {
+				fmt.Println(indentLines(out, "     "))
+			}
+		default:
+			yellow.Printf("⏭ %d. %s (did not run)\n", i+1, step.Description)
+		}
+
+		if !step.Optional && (r.blocked || r.status != StepSucceeded) {
+			ok = false
+		}
+	}
+
+	cyan.Println("─────────────────────────────────────────────────────────────")
+	if ok {
+		green.Println("✅ Every required step succeeded in the sandbox")
+	} else {
+		yellow.Println("⚠️  Some required steps did not succeed in the sandbox")
+		yellow.Println("   Steps that need systemd, hardware or a desktop session may fail in a container")
+	}
+	return ok
+}
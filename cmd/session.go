@@ -0,0 +1,213 @@
+package cmd
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Session is a saved chat conversation
+type Session struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name,omitempty"`
+	Provider  string    `json:"provider"`
+	Model     string    `json:"model"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Messages  []Message `json:"messages"`
+}
+
+// newRecordID returns an ID such as "20240101-120000-a1b2" for a record
+// created at t. The random suffix keeps records started within the same
+// second from overwriting each other's files.
+func newRecordID(t time.Time) string {
+	suffix := make([]byte, 2)
+	rand.Read(suffix)
+	return t.Format("20060102-150405") + "-" + hex.EncodeToString(suffix)
+}
+
+// newSession starts an unsaved session for the current provider and model
+func newSession() *Session {
+	now := time.Now()
+	return &Session{
+		ID:        newRecordID(now),
+		Provider:  providerName,
+		Model:     model,
+		CreatedAt: now,
+	}
+}
+
+// Title returns the session name, or the first user message when unnamed
+func (s *Session) Title() string {
+	if s.Name != "" {
+		return s.Name
+	}
+	for _, msg := range s.Messages {
+		if msg.Role == RoleUser {
+			title := []rune(strings.Join(strings.Fields(msg.Content), " "))
+			if len(title) > 50 {
+				return string(title[:47]) + "..."
+			}
+			return string(title)
+		}
+	}
+	return "(empty)"
+}
+
+// sessionsDir returns the directory that stores saved sessions
+func sessionsDir() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "sessions"), nil
+}
+
+// saveSession writes a session to disk, updating its timestamp
+func saveSession(s *Session) error {
+	dir, err := sessionsDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create sessions directory: %w", err)
+	}
+
+	s.UpdatedAt = time.Now()
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, s.ID+".json"), data, 0o600)
+}
+
+// listSessions returns all saved sessions, most recently updated first
+func listSessions() ([]*Session, error) {
+	dir, err := sessionsDir()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var sessions []*Session
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+
+		s, err := readSession(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		sessions = append(sessions, s)
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].UpdatedAt.After(sessions[j].UpdatedAt)
+	})
+	return sessions, nil
+}
+
+func readSession(path string) (*Session, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var s Session
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse session %s: %w", path, err)
+	}
+	return &s, nil
+}
+
+// findSession resolves a session by ID, name or unique ID prefix.
+// An empty ref selects the most recently updated session.
+func findSession(ref string) (*Session, error) {
+	sessions, err := listSessions()
+	if err != nil {
+		return nil, err
+	}
+	if len(sessions) == 0 {
+		return nil, fmt.Errorf("no saved sessions")
+	}
+
+	if ref == "" {
+		return sessions[0], nil
+	}
+
+	for _, s := range sessions {
+		if s.ID == ref || s.Name == ref {
+			return s, nil
+		}
+	}
+
+	var matches []*Session
+	for _, s := range sessions {
+		if strings.HasPrefix(s.ID, ref) {
+			matches = append(matches, s)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("session %q not found", ref)
+	case 1:
+		return matches[0], nil
+	default:
+		return nil, fmt.Errorf("session %q is ambiguous (%d matches)", ref, len(matches))
+	}
+}
+
+// deleteSession removes a saved session from disk
+func deleteSession(s *Session) error {
+	dir, err := sessionsDir()
+	if err != nil {
+		return err
+	}
+	return os.Remove(filepath.Join(dir, s.ID+".json"))
+}
+
+// sessionMarkdown renders a session as a shareable Markdown document
+func sessionMarkdown(s *Session) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# %s\n\n", s.Title())
+	fmt.Fprintf(&b, "- Session: `%s`\n", s.ID)
+	fmt.Fprintf(&b, "- Provider: %s\n", s.Provider)
+	fmt.Fprintf(&b, "- Model: %s\n", s.Model)
+	fmt.Fprintf(&b, "- Updated: %s\n", s.UpdatedAt.Format(time.RFC1123))
+
+	for _, msg := range s.Messages {
+		switch msg.Role {
+		case RoleSystem:
+			fmt.Fprintf(&b, "\n## System\n\n> %s\n", strings.ReplaceAll(msg.Content, "\n", "\n> "))
+		case RoleUser:
+			fmt.Fprintf(&b, "\n## You\n\n%s\n", msg.Content)
+		case RoleAssistant:
+			if msg.Content != "" {
+				fmt.Fprintf(&b, "\n## AI\n\n%s\n", msg.Content)
+			}
+			for _, call := range msg.ToolCalls {
+				fmt.Fprintf(&b, "\n### Tool call: %s\n\n```json\n%s\n```\n", call.Name, call.Arguments)
+			}
+		case RoleTool:
+			fmt.Fprintf(&b, "\n```\n%s\n```\n", strings.TrimRight(msg.Content, "\n"))
+		}
+	}
+	return b.String()
+}
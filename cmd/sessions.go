@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportFormat string
+	exportFile   string
+)
+
+var sessionsCmd = &cobra.Command{
+	Use:   "sessions",
+	Short: "Manage saved chat sessions",
+	Long: `List, inspect, delete and export chat sessions saved with /save.
+
+Sessions can be referenced by ID, name or a unique ID prefix.
+
+Examples:
+  livecli sessions list
+  livecli sessions show docker-debug
+  livecli sessions export docker-debug --format markdown --file debug.md
+  livecli chat --resume docker-debug`,
+}
+
+var sessionsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List saved sessions",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sessions, err := listSessions()
+		if err != nil {
+			return err
+		}
+
+		if len(sessions) == 0 {
+			fmt.Println("No saved sessions. Use /save inside 'livecli chat' to create one.")
+			return nil
+		}
+
+		cyan := color.New(color.FgCyan, color.Bold)
+		cyan.Printf("%-17s  %-16s  %-5s  %s\n", "ID", "UPDATED", "MSGS", "TITLE")
+		for _, s := range sessions {
+			fmt.Printf("%-17s  %-16s  %-5d  %s\n",
+				s.ID, s.UpdatedAt.Format("2006-01-02 15:04"), len(s.Messages), s.Title())
+		}
+		return nil
+	},
+}
+
+var sessionsShowCmd = &cobra.Command{
+	Use:   "show <session>",
+	Short: "Print a saved session",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := findSession(args[0])
+		if err != nil {
+			return err
+		}
+
+		printSessionHistory(s)
+		return nil
+	},
+}
+
+var sessionsDeleteCmd = &cobra.Command{
+	Use:   "delete <session>",
+	Short: "Delete a saved session",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := findSession(args[0])
+		if err != nil {
+			return err
+		}
+
+		if err := deleteSession(s); err != nil {
+			return err
+		}
+		color.Green("✓ Deleted session %s (%s)", s.ID, s.Title())
+		return nil
+	},
+}
+
+var sessionsExportCmd = &cobra.Command{
+	Use:   "export <session>",
+	Short: "Export a session to Markdown or JSON",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := findSession(args[0])
+		if err != nil {
+			return err
+		}
+
+		var data []byte
+		switch exportFormat {
+		case "markdown", "md":
+			data = []byte(sessionMarkdown(s))
+		case "json":
+			data, err = json.MarshalIndent(s, "", "  ")
+			if err != nil {
+				return err
+			}
+			data = append(data, '\n')
+		default:
+			return fmt.Errorf("unknown export format %q (use markdown or json)", exportFormat)
+		}
+
+		if exportFile == "" || exportFile == "-" {
+			_, err = resultOut.Write(data)
+			return err
+		}
+
+		if err := os.WriteFile(exportFile, data, 0o644); err != nil {
+			return err
+		}
+		color.Green("✓ Exported session %s to %s", s.ID, exportFile)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(sessionsCmd)
+	sessionsCmd.AddCommand(sessionsListCmd, sessionsShowCmd, sessionsDeleteCmd, sessionsExportCmd)
+
+	sessionsExportCmd.Flags().StringVarP(&exportFormat, "format", "f", "markdown", "Export format: markdown or json")
+	sessionsExportCmd.Flags().StringVar(&exportFile, "file", "", "Write to file instead of stdout")
+}
+
+// maxSessionToolOutput bounds the tool results shown when replaying a session
+const maxSessionToolOutput = 500
+
+// printSessionHistory prints a session's conversation to the terminal
+func printSessionHistory(s *Session) {
+	cyan := color.New(color.FgCyan, color.Bold)
+	magenta := color.New(color.FgMagenta, color.Bold)
+	green := color.New(color.FgGreen, color.Bold)
+	yellow := color.New(color.FgYellow)
+
+	cyan.Printf("\n💬 %s\n", s.Title())
+	fmt.Printf("ID: %s  Provider: %s  Model: %s\n", s.ID, s.Provider, s.Model)
+
+	for _, msg := range s.Messages {
+		switch msg.Role {
+		case RoleUser:
+			magenta.Print("\nYou> ")
+			fmt.Println(msg.Content)
+		case RoleAssistant:
+			if msg.Content != "" {
+				green.Print("\nAI> ")
+				fmt.Println(msg.Content)
+			}
+			for _, call := range msg.ToolCalls {
+				yellow.Printf("\n🔧 %s %s\n", call.Name, call.Arguments)
+			}
+		case RoleTool:
+			fmt.Println(indentLines(truncateOutput(msg.Content, maxSessionToolOutput), "   "))
+		}
+	}
+	fmt.Println()
+}
@@ -2,15 +2,13 @@ package cmd
 
 import (
 	"bufio"
-	"context"
-	"encoding/json"
 	"fmt"
 	"os"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
-	openai "github.com/sashabaranov/go-openai"
 	"github.com/spf13/cobra"
 )
 
@@ -18,15 +16,26 @@ type SetupStep struct {
 	Command     string `json:"command"`
 	Description string `json:"description"`
 	Optional    bool   `json:"optional"`
+	Undo        string `json:"undo,omitempty"`
+	Check       string `json:"check,omitempty"`
 }
 
 type SetupPlan struct {
-	Steps []SetupStep `json:"steps"`
+	Diagnosis string      `json:"diagnosis,omitempty"`
+	Steps     []SetupStep `json:"steps"`
 }
 
 var (
 	autoConfirm bool
 	dryRun      bool
+	onError     string
+	riskSpec    string
+	riskPolicy  map[RiskLevel]RiskAction
+	rollbackRun string
+	maxRepairs  int
+	savePlan    string
+	fromPlan    string
+	sandboxMode string
 )
 
 var setupCmd = &cobra.Command{
@@ -41,11 +50,25 @@ Examples:
   livecli setup "rust into my system"
   livecli setup "docker and docker-compose"
   livecli setup "nodejs version 18"
-  livecli setup "vscode editor"`,
-	Args: cobra.MinimumNArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		task := strings.Join(args, " ")
-		executeSetup(task)
+  livecli setup "vscode editor"
+  livecli setup "postgresql" --dry-run --save-plan postgres-plan.json
+  livecli setup --from-plan postgres-plan.json
+  livecli setup "redis" --sandbox
+  livecli setup --rollback 20240101-120000`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if cmd.Flags().Changed("rollback") || cmd.Flags().Changed("from-plan") {
+			if len(args) > 0 {
+				return fmt.Errorf("--rollback and --from-plan do not take a task description")
+			}
+			return nil
+		}
+		return cobra.MinimumNArgs(1)(cmd, args)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if rollbackRun != "" {
+			return rollbackSetup(rollbackRun)
+		}
+		return executeSetup(strings.Join(args, " "))
 	},
 }
 
@@ -54,14 +77,52 @@ func init() {
 
 	setupCmd.Flags().BoolVarP(&autoConfirm, "yes", "y", false, "Auto-confirm all commands")
 	setupCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show commands without executing")
+	setupCmd.Flags().
+		StringVar(&onError, "on-error", "ask", "What to do when a step fails: ask, stop or continue")
+	setupCmd.Flags().StringVar(
+		&riskSpec,
+		"risk-policy",
+		"",
+		"Override actions per risk level, e.g. \"high=block,medium=confirm\" (actions: allow, confirm, block)",
+	)
+	setupCmd.Flags().IntVar(&maxRepairs, "max-repairs", 2, "How many times the AI may revise the plan after a failed step")
+	setupCmd.Flags().StringVar(
+		&rollbackRun,
+		"rollback",
+		"",
+		"Undo the steps of a recorded setup run in reverse order (run ID, default latest)",
+	)
+	setupCmd.Flags().Lookup("rollback").NoOptDefVal = "latest"
+	setupCmd.Flags().StringVar(&savePlan, "save-plan", "", "Save the generated plan to a file for review and replay")
+	setupCmd.Flags().StringVar(&fromPlan, "from-plan", "", "Run a saved plan file instead of asking the AI")
+	setupCmd.Flags().StringVar(&sandboxMode, "sandbox", "", "Test the plan in a throwaway sandbox first (auto, podman, docker, bwrap)")
+	setupCmd.Flags().Lookup("sandbox").NoOptDefVal = "auto"
 }
 
-func executeSetup(task string) {
-	if apiKey == "" {
-		color.Red(
-			"Error: OpenAI API key not set. Use --api-key flag or set OPENAI_API_KEY environment variable.",
-		)
-		return
+// validateSetupFlags checks the flags shared by setup and rollback and
+// parses the risk policy
+func validateSetupFlags() error {
+	if onError != "ask" && onError != "stop" && onError != "continue" {
+		return fmt.Errorf("invalid --on-error value %q (use ask, stop or continue)", onError)
+	}
+
+	policy, err := parseRiskPolicy(riskSpec)
+	if err != nil {
+		return err
+	}
+	riskPolicy = policy
+	return nil
+}
+
+func executeSetup(task string) error {
+	// A saved plan runs without the AI, which is then only needed for repairs
+	provider, err := newProvider()
+	if err != nil && fromPlan == "" {
+		return err
+	}
+
+	if err := validateSetupFlags(); err != nil {
+		return err
 	}
 
 	cyan := color.New(color.FgCyan, color.Bold)
@@ -69,31 +130,62 @@ func executeSetup(task string) {
 	yellow := color.New(color.FgYellow, color.Bold)
 	magenta := color.New(color.FgMagenta, color.Bold)
 
-	cyan.Println("\n╔═══════════════════════════════════════════════════════════╗")
-	cyan.Println("║           🤖 AI Setup Assistant                           ║")
-	cyan.Println("╚═══════════════════════════════════════════════════════════╝")
+	printBanner(cyan, cyan, "║           🤖 AI Setup Assistant                           ║")
 
-	fmt.Printf("\n📋 Task: %s\n", task)
-	yellow.Println("\n⏳ Analyzing your request and generating setup plan...")
-
-	// Get setup plan from AI
-	plan, err := generateSetupPlan(task)
-	if err != nil {
-		color.Red("\n❌ Error generating setup plan: %v", err)
-		return
+	var plan SetupPlan
+	audit := aiAudit("setup", task)
+	if fromPlan != "" {
+		file, err := loadPlanFile(fromPlan)
+		if err != nil {
+			return fmt.Errorf("loading the plan: %w", err)
+		}
+		task, plan = file.Task, SetupPlan{Steps: file.Steps}
+		audit.Task, audit.Provider, audit.Model = file.Task, file.Provider, file.Model
+
+		fmt.Printf("\n📋 Task: %s\n", task)
+		fmt.Printf("📂 Plan: %s (generated by %s on %s)\n",
+			fromPlan, file.Model, file.CreatedAt.Format(time.RFC1123))
+		if current := detectOS(); file.OS != current {
+			yellow.Printf("\n⚠️  This plan was generated for %s, but this system is %s.\n", file.OS, current)
+			yellow.Println("   Review every command carefully before running it.")
+		}
+	} else {
+		fmt.Printf("\n📋 Task: %s\n", task)
+		fmt.Printf("🖥️  System: %s\n", probeSystem().Summary())
+		yellow.Println("\n⏳ Analyzing your request and generating setup plan...")
+
+		// Get setup plan from AI
+		plan, err = generateSetupPlan(provider, task)
+		if err != nil {
+			return fmt.Errorf("generating the setup plan: %w", err)
+		}
 	}
 
 	if len(plan.Steps) == 0 {
 		color.Yellow(
 			"\n⚠️  No setup steps were generated. The task might already be complete or unclear.",
 		)
-		return
+		return nil
+	}
+
+	// Run the idempotency checks, once the user agrees, so the plan shows what
+	// is already in place. A dry run executes nothing, not even checks.
+	checks := make([]checkStatus, len(plan.Steps))
+	var checkApproval string
+	if !dryRun {
+		var runChecks bool
+		if checkApproval, runChecks = confirmChecks(plan.Steps); runChecks {
+			for i, step := range plan.Steps {
+				checks[i] = checkStep(step, audit, checkApproval)
+			}
+		}
 	}
 
 	// Display the plan
 	cyan.Println("\n📝 Setup Plan:")
 	cyan.Println("─────────────────────────────────────────────────────────────")
 
+	pending := 0
 	for i, step := range plan.Steps {
 		if step.Optional {
 			yellow.Printf("\n%d. [OPTIONAL] %s\n", i+1, step.Description)
@@ -101,48 +193,226 @@ func executeSetup(task string) {
 			fmt.Printf("\n%d. %s\n", i+1, step.Description)
 		}
 		magenta.Printf("   Command: %s\n", step.Command)
+		if step.Undo != "" {
+			fmt.Printf("   Undo:    %s\n", step.Undo)
+		}
+		printCheckStatus(step, checks[i])
+		if checks[i] != checkSatisfied {
+			pending++
+		}
+
+		assessment := classifyCommand(step.Command)
+		printRiskAssessment(assessment, riskPolicy[assessment.Level], "   ")
 	}
 
 	cyan.Println("\n─────────────────────────────────────────────────────────────")
 
+	if satisfied := len(plan.Steps) - pending; satisfied > 0 {
+		fmt.Printf("\n📊 %d pending, %d already satisfied\n", pending, satisfied)
+	}
+
+	if savePlan != "" {
+		if err := savePlanFile(savePlan, task, plan); err != nil {
+			return fmt.Errorf("saving the plan: %w", err)
+		}
+		green.Printf("\n💾 Plan saved to %s\n", savePlan)
+	}
+
+	// Scripts get the plan itself; everything shown above went to stderr
+	if dryRun && jsonOutput() {
+		writeJSON(plan)
+	}
+
+	if pending == 0 {
+		green.Println("\n✅ Every step is already satisfied. Nothing to do.")
+		return nil
+	}
+
+	// Try the plan in a sandbox first; the host is only offered afterwards
+	if sandboxMode != "" {
+		sandboxApproval := approvalAuto
+		if !autoConfirm {
+			fmt.Print("\n❓ Test this plan in the sandbox? (yes/no): ")
+			response, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+			response = strings.TrimSpace(strings.ToLower(response))
+			if response != "yes" && response != "y" {
+				yellow.Println("\n❌ Setup cancelled by user.")
+				return nil
+			}
+			sandboxApproval = approvalConfirmed
+		}
+
+		passed, err := sandboxPlan(sandboxMode, plan.Steps, audit, sandboxApproval)
+		if err != nil {
+			return fmt.Errorf("sandbox run failed: %w", err)
+		}
+		if dryRun {
+			green.Println("\n✓ Sandbox run complete. No commands were executed on the host.")
+			if !passed {
+				return fmt.Errorf("the plan failed in the sandbox")
+			}
+			return nil
+		}
+		if !passed && autoConfirm {
+			yellow.Println("\n⏹️  Not running on the host because the plan failed in the sandbox.")
+			return fmt.Errorf("the plan failed in the sandbox")
+		}
+	}
+
 	// Ask for overall confirmation
 	if !autoConfirm && !dryRun {
-		fmt.Print("\n❓ Do you want to proceed with this setup plan? (yes/no): ")
+		question := "Do you want to proceed with this setup plan?"
+		if sandboxMode != "" {
+			question = "Run this plan on the host?"
+		}
+		fmt.Printf("\n❓ %s (yes/no): ", question)
 		reader := bufio.NewReader(os.Stdin)
 		response, _ := reader.ReadString('\n')
 		response = strings.TrimSpace(strings.ToLower(response))
 
 		if response != "yes" && response != "y" {
 			yellow.Println("\n❌ Setup cancelled by user.")
-			return
+			return nil
 		}
 	}
 
 	if dryRun {
 		green.Println("\n✓ Dry run complete. No commands were executed.")
-		return
+		return nil
 	}
 
 	// Execute each step
 	green.Println("\n\n🚀 Starting setup process...")
 
-	for i, step := range plan.Steps {
+	// Record which steps ran as they run, so an interrupted setup can still be rolled back
+	run := newSetupRun(task, plan)
+	hooks := stepHooks{
+		checkApproval: checkApproval,
+		onResult: func(steps []SetupStep, i int, result StepResult) {
+			run.record(steps, i, result)
+			if err := saveSetupRun(run); err != nil {
+				color.Red("Error recording setup run: %v", err)
+			}
+		},
+	}
+	if provider != nil {
+		hooks.repair = func(steps []SetupStep, results []StepResult, i int) (SetupPlan, error) {
+			return repairSetupPlan(provider, task, steps, results, i)
+		}
+	}
+
+	steps, results := runSetupSteps(plan.Steps, audit, hooks)
+	printSetupSummary(steps, results)
+
+	if jsonOutput() {
+		report := SetupReport{Task: task, RunID: run.ID}
+		for i, result := range results {
+			report.Steps = append(report.Steps, newStepReport(steps[i].Description, result))
+		}
+		writeJSON(report)
+	}
+
+	if len(run.undoable()) > 0 {
+		fmt.Printf("↩️  Undo this run with: livecli setup --rollback %s\n\n", run.ID)
+	}
+	return incompleteSteps(steps, results)
+}
+
+// SetupReport is the JSON output of a setup run
+type SetupReport struct {
+	Task  string       `json:"task"`
+	RunID string       `json:"run_id"`
+	Steps []StepReport `json:"steps"`
+}
+
+// stepHooks lets callers observe and extend step execution
+type stepHooks struct {
+	// checkApproval is how the user agreed to run checks before each step;
+	// empty when they declined, so no checks run
+	checkApproval string
+	// onResult is called after each executed step with the current plan
+	onResult func(steps []SetupStep, i int, result StepResult)
+	// repair proposes the steps that should follow the failed steps[i];
+	// nil disables AI repair
+	repair func(steps []SetupStep, results []StepResult, i int) (SetupPlan, error)
+}
+
+// failureAction is what to do after a required step failed
+type failureAction int
+
+const (
+	failureStop failureAction = iota
+	failureContinue
+	failureRepair
+)
+
+// runSetupSteps executes the approved steps in order and returns the final plan
+// with one result per step. Steps that were never run are reported as skipped.
+// An approved AI repair replaces the steps after the failed one. Every executed
+// command is recorded in the audit log based on the audit template.
+func runSetupSteps(steps []SetupStep, audit AuditEntry, hooks stepHooks) ([]SetupStep, []StepResult) {
+	cyan := color.New(color.FgCyan, color.Bold)
+	green := color.New(color.FgGreen, color.Bold)
+	yellow := color.New(color.FgYellow, color.Bold)
+	magenta := color.New(color.FgMagenta, color.Bold)
+	red := color.New(color.FgRed, color.Bold)
+
+	results := make([]StepResult, len(steps))
+	for i, step := range steps {
+		results[i] = StepResult{Command: step.Command, Status: StepSkipped}
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	repairs := 0
+
+loop:
+	for i := 0; i < len(steps); i++ {
+		step := steps[i]
+
 		cyan.Printf("\n\n━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
-		cyan.Printf("Step %d/%d\n", i+1, len(plan.Steps))
+		cyan.Printf("Step %d/%d\n", i+1, len(steps))
 		cyan.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
 
 		fmt.Printf("\n📌 %s\n", step.Description)
 		magenta.Printf("💻 Command: %s\n", step.Command)
 
-		// Ask for confirmation for each step
-		if !autoConfirm {
+		// Checks run again here, since earlier steps may have satisfied this one
+		if hooks.checkApproval != "" && checkStep(step, audit, hooks.checkApproval) == checkSatisfied {
+			green.Printf("✓ Already satisfied (%s), skipped\n", step.Check)
+			results[i] = StepResult{Command: step.Command, Status: StepSatisfied}
+			if hooks.onResult != nil {
+				hooks.onResult(steps, i, results[i])
+			}
+			continue
+		}
+
+		// Enforce the safety policy, even under --yes
+		assessment := classifyCommand(step.Command)
+		action := riskPolicy[assessment.Level]
+		printRiskAssessment(assessment, action, "")
+
+		if action == RiskBlock {
+			red.Println("🚫 Blocked by risk policy, not executed")
+			continue
+		}
+
+		approval := approvalAuto
+		if action == RiskConfirm {
+			if !typedConfirmation(reader, assessment.Level) {
+				yellow.Println("⏭️  Not confirmed, skipped")
+				continue
+			}
+			approval = approvalTyped
+		} else if !autoConfirm {
+			approval = approvalConfirmed
+
+			// Ask for confirmation for each step
 			if step.Optional {
 				fmt.Print("\n❓ Execute this optional step? (yes/no/skip): ")
 			} else {
 				fmt.Print("\n❓ Execute this command? (yes/no/skip all): ")
 			}
 
-			reader := bufio.NewReader(os.Stdin)
 			response, _ := reader.ReadString('\n')
 			response = strings.TrimSpace(strings.ToLower(response))
 
@@ -157,41 +427,212 @@ func executeSetup(task string) {
 			}
 		}
 
-		green.Printf("\n✓ Step %d/%d completed\n", i+1, len(plan.Steps))
+		fmt.Println()
+		result := runCommand(step.Command)
+		results[i] = result
+
+		entry := audit
+		entry.Approval = approval
+		entry.Risk = assessment.Level.String()
+		recordAudit(entry, result)
+		if hooks.onResult != nil {
+			hooks.onResult(steps, i, result)
+		}
+
+		if result.Status == StepSucceeded {
+			green.Printf("\n✓ Step %d/%d completed in %s\n", i+1, len(steps), formatDuration(result.Duration))
+			continue
+		}
+
+		red.Printf("\n❌ Step %d/%d failed (exit code %d) after %s\n",
+			i+1, len(steps), result.ExitCode, formatDuration(result.Duration))
+
+		if step.Optional {
+			yellow.Println("⚠️  Optional step failed, continuing")
+			continue
+		}
+
+		for {
+			canRepair := hooks.repair != nil && repairs < maxRepairs
+			switch afterFailure(reader, canRepair) {
+			case failureContinue:
+				continue loop
+			case failureStop:
+				yellow.Println("\n⏹️  Stopping setup after failed step")
+				break loop
+			}
+
+			repairs++
+			yellow.Printf("\n🩺 Asking AI to diagnose the failure (attempt %d/%d)...\n", repairs, maxRepairs)
+
+			revised, err := hooks.repair(steps, results, i)
+			if err != nil {
+				color.Red("❌ Diagnosis failed: %v", err)
+				continue
+			}
+			if !approveRepair(reader, revised, steps[i+1:]) {
+				yellow.Println("⏭️  Revised plan rejected")
+				continue
+			}
+
+			steps = append(steps[:i+1:i+1], revised.Steps...)
+			results = results[: i+1 : i+1]
+			for _, step := range revised.Steps {
+				results = append(results, StepResult{Command: step.Command, Status: StepSkipped})
+			}
+			green.Println("✓ Continuing with the revised plan")
+			continue loop
+		}
+	}
+
+	return steps, results
+}
+
+// afterFailure applies the --on-error policy to a failed step. When canRepair
+// is set the user may also ask the AI to diagnose the failure.
+func afterFailure(reader *bufio.Reader, canRepair bool) failureAction {
+	switch onError {
+	case "continue":
+		return failureContinue
+	case "stop":
+		return failureStop
 	}
 
-	// Final summary
-	cyan.Println("\n\n╔═══════════════════════════════════════════════════════════╗")
-	green.Println("║           ✅ Setup Complete!                              ║")
-	cyan.Println("╚═══════════════════════════════════════════════════════════╝")
+	// "ask" cannot prompt when running unattended
+	if autoConfirm {
+		return failureStop
+	}
+
+	if !canRepair {
+		fmt.Print("\n❓ Continue with the remaining steps? (yes/no): ")
+		response, _ := reader.ReadString('\n')
+		response = strings.TrimSpace(strings.ToLower(response))
+		if response == "yes" || response == "y" {
+			return failureContinue
+		}
+		return failureStop
+	}
 
-	fmt.Printf("\n✓ Executed %d steps successfully\n", len(plan.Steps))
-	green.Println("\n💡 Tip: Verify the installation with relevant commands (e.g., version checks)")
+	fmt.Print("\n❓ [a]sk AI to diagnose, [c]ontinue with the remaining steps or [s]top? ")
+	response, _ := reader.ReadString('\n')
+	switch strings.TrimSpace(strings.ToLower(response)) {
+	case "a", "ask":
+		return failureRepair
+	case "c", "continue", "y", "yes":
+		return failureContinue
+	default:
+		return failureStop
+	}
+}
+
+// incompleteSteps returns an error when a required step failed, or was not
+// run while running unattended, so scripts see a non-zero exit. Steps the user
+// chose to skip are not an error.
+func incompleteSteps(steps []SetupStep, results []StepResult) error {
+	required, incomplete := 0, 0
+	for i, result := range results {
+		if steps[i].Optional {
+			continue
+		}
+		required++
+		if result.Status == StepFailed || (result.Status == StepSkipped && autoConfirm) {
+			incomplete++
+		}
+	}
+	if incomplete > 0 {
+		return fmt.Errorf("%d of %d required steps did not complete", incomplete, required)
+	}
+	return nil
+}
+
+// printSetupSummary reports how many steps succeeded, failed or were skipped
+func printSetupSummary(steps []SetupStep, results []StepResult) {
+	cyan := color.New(color.FgCyan, color.Bold)
+	green := color.New(color.FgGreen, color.Bold)
+	yellow := color.New(color.FgYellow, color.Bold)
+	red := color.New(color.FgRed, color.Bold)
+
+	var succeeded, satisfied, failed, skipped int
+	for _, result := range results {
+		switch result.Status {
+		case StepSucceeded:
+			succeeded++
+		case StepSatisfied:
+			satisfied++
+		case StepFailed:
+			failed++
+		case StepSkipped:
+			skipped++
+		}
+	}
+
+	banner, bannerColor := "║           ✅ Setup Complete!                              ║", green
+	switch {
+	case failed > 0:
+		banner, bannerColor = "║           ❌ Setup Finished With Errors                   ║", red
+	case skipped > 0:
+		banner, bannerColor = "║           ⚠️  Setup Partially Complete                     ║", yellow
+	}
+	fmt.Println()
+	printBanner(cyan, bannerColor, banner)
+
+	fmt.Printf("\n✓ Succeeded: %d\n", succeeded)
+	if satisfied > 0 {
+		fmt.Printf("✓ Already satisfied: %d\n", satisfied)
+	}
+	fmt.Printf("✗ Failed:    %d\n", failed)
+	fmt.Printf("⏭ Skipped:   %d\n", skipped)
+
+	if failed > 0 {
+		red.Println("\nFailed steps:")
+		for i, result := range results {
+			if result.Status != StepFailed {
+				continue
+			}
+			fmt.Printf("  %d. %s (exit code %d)\n", i+1, steps[i].Description, result.ExitCode)
+			color.Magenta("     Command: %s", result.Command)
+		}
+	}
+
+	if failed == 0 && succeeded > 0 {
+		green.Println("\n💡 Tip: Verify the installation with relevant commands (e.g., version checks)")
+	}
 	fmt.Println()
 }
 
-func generateSetupPlan(task string) (SetupPlan, error) {
-	ctx := context.Background()
-	client := openai.NewClient(apiKey)
+// formatDuration rounds a duration for display
+func formatDuration(d time.Duration) string {
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	return d.Round(100 * time.Millisecond).String()
+}
 
-	// Detect OS
-	osInfo := detectOS()
+func generateSetupPlan(provider Provider, task string) (SetupPlan, error) {
+	// Describe the system so the plan fits it
+	system := probeSystem().PromptContext()
 
 	systemPrompt := fmt.Sprintf(
 		`You are an expert system administrator and DevOps engineer. Generate a precise, safe setup plan for the user's request.
 
-Operating System: %s
+System:
+%s
 Task: %s
 
 IMPORTANT RULES:
-1. Generate ONLY the necessary commands for THIS specific OS
-2. Use the system's package manager (apt, dnf, yum, brew, etc.)
+1. Generate ONLY the necessary commands for THIS specific system
+2. Use one of the package managers listed above
 3. Each command should be safe and commonly used
 4. Include verification commands when helpful
 5. Mark optional steps (like adding to PATH if it's automatic)
 6. Keep commands simple and atomic (one logical action per command)
-7. Include sudo only when absolutely necessary
+7. Include sudo only when absolutely necessary, and only if the privileges above allow it
 8. For URLs/downloads, use official sources only
+9. For every step that changes the system, add an "undo" command that reverses it
+   (e.g. uninstall a package, remove a created file); omit "undo" for read-only steps
+10. Do not reinstall tools that are already installed unless the task asks for a different version
+11. Add a "check" command when a quick, read-only command can tell whether the step is already done
+    (e.g. "docker --version", "test -d ~/.cargo"); it must exit 0 only if the step can be skipped
 
 Respond with ONLY a valid JSON object in this EXACT format (no markdown, no explanation):
 {
@@ -199,7 +640,9 @@ Respond with ONLY a valid JSON object in this EXACT format (no markdown, no expl
     {
       "command": "the exact command to run",
       "description": "brief description of what this does",
-      "optional": false
+      "optional": false,
+      "undo": "the command that reverses this step",
+      "check": "a read-only command that succeeds if the step is already done"
     }
   ]
 }
@@ -208,61 +651,27 @@ Example for "install docker":
 {
   "steps": [
     {"command": "sudo apt update", "description": "Update package index", "optional": false},
-    {"command": "sudo apt install -y docker.io", "description": "Install Docker", "optional": false},
-    {"command": "sudo systemctl start docker", "description": "Start Docker service", "optional": false},
-    {"command": "sudo systemctl enable docker", "description": "Enable Docker on boot", "optional": false},
-    {"command": "sudo usermod -aG docker $USER", "description": "Add user to docker group", "optional": true},
+    {"command": "sudo apt install -y docker.io", "description": "Install Docker", "optional": false, "undo": "sudo apt remove -y docker.io", "check": "command -v docker"},
+    {"command": "sudo systemctl start docker", "description": "Start Docker service", "optional": false, "undo": "sudo systemctl stop docker"},
+    {"command": "sudo systemctl enable docker", "description": "Enable Docker on boot", "optional": false, "undo": "sudo systemctl disable docker", "check": "systemctl is-enabled docker"},
+    {"command": "sudo usermod -aG docker $USER", "description": "Add user to docker group", "optional": true, "undo": "sudo gpasswd -d $USER docker"},
     {"command": "docker --version", "description": "Verify Docker installation", "optional": false}
   ]
 }`,
-		osInfo,
+		system,
 		task,
 	)
 
-	resp, err := client.CreateChatCompletion(
-		ctx,
-		openai.ChatCompletionRequest{
-			Model: model,
-			Messages: []openai.ChatCompletionMessage{
-				{
-					Role:    openai.ChatMessageRoleSystem,
-					Content: systemPrompt,
-				},
-				{
-					Role:    openai.ChatMessageRoleUser,
-					Content: fmt.Sprintf("Generate setup commands for: %s", task),
-				},
-			},
-			Temperature: 0.3, // Lower temperature for more consistent output
-			MaxTokens:   2000,
+	return requestPlan(provider, []Message{
+		{
+			Role:    RoleSystem,
+			Content: systemPrompt,
 		},
-	)
-	if err != nil {
-		return SetupPlan{}, fmt.Errorf("AI request failed: %w", err)
-	}
-
-	if len(resp.Choices) == 0 {
-		return SetupPlan{}, fmt.Errorf("no response from AI")
-	}
-
-	content := resp.Choices[0].Message.Content
-
-	// Clean up the response (remove markdown code blocks if present)
-	content = strings.TrimSpace(content)
-	content = strings.TrimPrefix(content, "```json")
-	content = strings.TrimPrefix(content, "```")
-	content = strings.TrimSuffix(content, "```")
-	content = strings.TrimSpace(content)
-
-	// Parse JSON response
-	var plan SetupPlan
-	if err := json.Unmarshal([]byte(content), &plan); err != nil {
-		// If JSON parsing fails, show what we got
-		fmt.Printf("Debug - AI Response:\n%s\n", content)
-		return SetupPlan{}, fmt.Errorf("failed to parse AI response as JSON: %w", err)
-	}
-
-	return plan, nil
+		{
+			Role:    RoleUser,
+			Content: fmt.Sprintf("Generate setup commands for: %s", task),
+		},
+	})
 }
 
 func detectOS() string {
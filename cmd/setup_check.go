@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// checkTimeout bounds how long a step's check command may run
+const checkTimeout = 15 * time.Second
+
+// checkStatus is the outcome of a step's idempotency check
+type checkStatus int
+
+const (
+	checkNone checkStatus = iota
+	checkPending
+	checkSatisfied
+	checkUnsafe
+)
+
+// confirmChecks lists the plan's check commands and asks whether to run them.
+// Checks come from the AI or a plan file, so like steps they never run without
+// consent; --yes gives it for the whole plan. It returns the approval to record
+// for the checks, and false when they must not run.
+func confirmChecks(steps []SetupStep) (string, bool) {
+	if autoConfirm {
+		return approvalAuto, true
+	}
+
+	runnable := 0
+	for _, step := range steps {
+		if step.Check != "" && classifyCommand(step.Check).Level == RiskLow {
+			runnable++
+		}
+	}
+	if runnable == 0 {
+		return "", false
+	}
+
+	color.New(color.FgCyan, color.Bold).Println("\n🔍 Checks, to find steps that are already done:")
+	for i, step := range steps {
+		if step.Check == "" {
+			continue
+		}
+		fmt.Printf("   %d. %s", i+1, step.Check)
+		if level := classifyCommand(step.Check).Level; level > RiskLow {
+			riskColor(level).Printf("  ⚠️  will not run (%s risk)", level)
+		}
+		fmt.Println()
+	}
+
+	fmt.Print("\n❓ Run these checks before showing the plan? (yes/no): ")
+	response, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	response = strings.TrimSpace(strings.ToLower(response))
+	if response != "yes" && response != "y" {
+		return "", false
+	}
+	return approvalConfirmed, true
+}
+
+// checkStep runs a step's check command, if it has one, and records it in the
+// audit log with the given approval. Only low-risk checks are executed, and
+// only once the user agreed to run checks.
+func checkStep(step SetupStep, audit AuditEntry, approval string) checkStatus {
+	if step.Check == "" {
+		return checkNone
+	}
+	if classifyCommand(step.Check).Level > RiskLow {
+		return checkUnsafe
+	}
+
+	result := runQuiet(step.Check, checkTimeout)
+
+	audit.Source += "-check"
+	audit.Approval = approval
+	audit.Risk = RiskLow.String()
+	recordAudit(audit, result)
+
+	if result.Status == StepSucceeded {
+		return checkSatisfied
+	}
+	return checkPending
+}
+
+// printCheckStatus shows a step's check command and its outcome in the plan
+func printCheckStatus(step SetupStep, status checkStatus) {
+	if step.Check == "" {
+		return
+	}
+
+	fmt.Printf("   Check:   %s", step.Check)
+	switch status {
+	case checkSatisfied:
+		color.New(color.FgGreen, color.Bold).Println("  ✓ already satisfied")
+	case checkPending:
+		color.New(color.FgYellow).Println("  ○ pending")
+	case checkUnsafe:
+		level := classifyCommand(step.Check).Level
+		riskColor(level).Printf("  ⚠️  not run (%s risk)\n", level)
+	default:
+		fmt.Println("  – not run")
+	}
+}
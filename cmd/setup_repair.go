@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// maxRepairOutput bounds how much of a failed step's output is sent to the model
+const maxRepairOutput = 3000
+
+const repairPrompt = `You are an expert system administrator and DevOps engineer. A step of a setup plan failed.
+Diagnose the failure and revise the rest of the plan so that the task can still be completed.
+
+System:
+%s
+RULES:
+1. Explain the most likely cause of the failure in one or two sentences
+2. Return the steps that should run NEXT; they replace every step that has not run yet
+3. Include a corrected version of the failed step if it still needs to happen
+4. Keep steps that still make sense unchanged, with the same command text
+5. Use a package manager listed above, official sources only and sudo only when necessary
+6. Add an "undo" command for every step that changes the system
+
+Respond with ONLY a valid JSON object in this EXACT format (no markdown, no explanation):
+{
+  "diagnosis": "why the step failed",
+  "steps": [
+    {"command": "the exact command to run", "description": "brief description", "optional": false, "undo": "the command that reverses this step", "check": "optional read-only command that succeeds if the step is already done"}
+  ]
+}`
+
+// repairSetupPlan sends the task, the plan so far and the output of the failed
+// steps[failed] to the model and returns its revised remainder of the plan
+func repairSetupPlan(provider Provider, task string, steps []SetupStep, results []StepResult, failed int) (SetupPlan, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Task: %s\n\nPlan so far:\n", task)
+	for i, step := range steps[:failed+1] {
+		fmt.Fprintf(&b, "%d. [%s] %s\n   $ %s\n", i+1, results[i].Status, step.Description, step.Command)
+	}
+
+	result := results[failed]
+	fmt.Fprintf(&b, "\nStep %d failed with exit code %d.\n", failed+1, result.ExitCode)
+	if stderr := strings.TrimSpace(result.Stderr); stderr != "" {
+		fmt.Fprintf(&b, "\nStderr:\n%s\n", truncateOutput(stderr, maxRepairOutput))
+	}
+	if stdout := strings.TrimSpace(result.Stdout); stdout != "" {
+		fmt.Fprintf(&b, "\nStdout:\n%s\n", truncateOutput(stdout, maxRepairOutput))
+	}
+
+	if remaining := steps[failed+1:]; len(remaining) > 0 {
+		b.WriteString("\nSteps that have not run yet:\n")
+		for i, step := range remaining {
+			fmt.Fprintf(&b, "%d. %s\n   $ %s\n", failed+i+2, step.Description, step.Command)
+		}
+	}
+
+	return requestPlan(provider, []Message{
+		{
+			Role:    RoleSystem,
+			Content: fmt.Sprintf(repairPrompt, probeSystem().PromptContext()),
+		},
+		{
+			Role:    RoleUser,
+			Content: b.String(),
+		},
+	})
+}
+
+// approveRepair shows the diagnosis and how the remaining steps would change,
+// and asks the user to accept the revision
+func approveRepair(reader *bufio.Reader, revised SetupPlan, remaining []SetupStep) bool {
+	cyan := color.New(color.FgCyan, color.Bold)
+
+	if revised.Diagnosis != "" {
+		cyan.Println("\n🩺 Diagnosis:")
+		fmt.Printf("   %s\n", revised.Diagnosis)
+	}
+
+	cyan.Println("\n📝 Revised remaining steps:")
+	cyan.Println("─────────────────────────────────────────────────────────────")
+	printStepDiff(remaining, revised.Steps)
+	cyan.Println("─────────────────────────────────────────────────────────────")
+
+	if len(revised.Steps) == 0 {
+		color.Yellow("The AI suggests no further steps")
+	}
+
+	fmt.Print("\n❓ Continue with the revised plan? (yes/no): ")
+	response, _ := reader.ReadString('\n')
+	response = strings.TrimSpace(strings.ToLower(response))
+	return response == "yes" || response == "y"
+}
+
+// printStepDiff shows old and new steps as a line diff of their commands
+func printStepDiff(old, updated []SetupStep) {
+	red := color.New(color.FgRed)
+	green := color.New(color.FgGreen)
+
+	// Longest common subsequence of commands, so unchanged steps line up
+	lcs := make([][]int, len(old)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(updated)+1)
+	}
+	for i := len(old) - 1; i >= 0; i-- {
+		for j := len(updated) - 1; j >= 0; j-- {
+			if old[i].Command == updated[j].Command {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(old) || j < len(updated) {
+		switch {
+		case i < len(old) && j < len(updated) && old[i].Command == updated[j].Command:
+			fmt.Printf("  %s\n", updated[j].Command)
+			i++
+			j++
+		case j < len(updated) && (i == len(old) || lcs[i][j+1] >= lcs[i+1][j]):
+			green.Printf("+ %s\n", updated[j].Command)
+			green.Printf("    %s\n", updated[j].Description)
+			assessment := classifyCommand(updated[j].Command)
+			printRiskAssessment(assessment, riskPolicy[assessment.Level], "    ")
+			j++
+		default:
+			red.Printf("- %s\n", old[i].Command)
+			i++
+		}
+	}
+}
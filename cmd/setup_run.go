@@ -0,0 +1,300 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// SetupRun records what a setup run executed so that it can be rolled back
+type SetupRun struct {
+	ID        string         `json:"id"`
+	Task      string         `json:"task"`
+	OS        string         `json:"os"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	Steps     []SetupRunStep `json:"steps"`
+}
+
+// SetupRunStep is a planned step and what happened when it was executed
+type SetupRunStep struct {
+	SetupStep
+	Status     StepStatus `json:"status"`
+	ExitCode   int        `json:"exit_code"`
+	RolledBack bool       `json:"rolled_back,omitempty"`
+}
+
+// newSetupRun starts a run record with every step marked as skipped
+func newSetupRun(task string, plan SetupPlan) *SetupRun {
+	now := time.Now()
+	run := &SetupRun{
+		ID:        newRecordID(now),
+		Task:      task,
+		OS:        detectOS(),
+		CreatedAt: now,
+	}
+	for _, step := range plan.Steps {
+		run.Steps = append(run.Steps, SetupRunStep{SetupStep: step, Status: StepSkipped})
+	}
+	return run
+}
+
+// Ran reports whether the step was executed, successfully or not
+func (s SetupRunStep) Ran() bool {
+	return s.Status == StepSucceeded || s.Status == StepFailed
+}
+
+// undoable returns the indexes of executed steps that have an undo command and
+// were not rolled back yet, latest first
+func (r *SetupRun) undoable() []int {
+	var indexes []int
+	for i := len(r.Steps) - 1; i >= 0; i-- {
+		step := r.Steps[i]
+		if step.Ran() && !step.RolledBack && step.Undo != "" {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+// record stores the result of steps[i], first adopting any steps that an AI
+// repair put in place of the original remainder of the plan
+func (r *SetupRun) record(steps []SetupStep, i int, result StepResult) {
+	updated := make([]SetupRunStep, 0, len(steps))
+	for j, step := range steps {
+		if j < len(r.Steps) && r.Steps[j].SetupStep == step {
+			updated = append(updated, r.Steps[j])
+		} else {
+			updated = append(updated, SetupRunStep{SetupStep: step, Status: StepSkipped})
+		}
+	}
+	r.Steps = updated
+
+	r.Steps[i].Status = result.Status
+	r.Steps[i].ExitCode = result.ExitCode
+}
+
+// setupRunsDir returns the directory that stores setup run records
+func setupRunsDir() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "runs"), nil
+}
+
+// saveSetupRun writes a run record to disk, updating its timestamp
+func saveSetupRun(r *SetupRun) error {
+	dir, err := setupRunsDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create runs directory: %w", err)
+	}
+
+	r.UpdatedAt = time.Now()
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, r.ID+".json"), data, 0o600)
+}
+
+// listSetupRuns returns all recorded runs, newest first
+func listSetupRuns() ([]*SetupRun, error) {
+	dir, err := setupRunsDir()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var runs []*SetupRun
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		var r SetupRun
+		if err := json.Unmarshal(data, &r); err != nil {
+			continue
+		}
+		runs = append(runs, &r)
+	}
+
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].CreatedAt.After(runs[j].CreatedAt)
+	})
+	return runs, nil
+}
+
+// findSetupRun resolves a run by ID or unique ID prefix.
+// "latest" or an empty ref selects the most recent run.
+func findSetupRun(ref string) (*SetupRun, error) {
+	runs, err := listSetupRuns()
+	if err != nil {
+		return nil, err
+	}
+	if len(runs) == 0 {
+		return nil, fmt.Errorf("no recorded setup runs")
+	}
+
+	if ref == "" || ref == "latest" {
+		return runs[0], nil
+	}
+
+	var matches []*SetupRun
+	for _, r := range runs {
+		if r.ID == ref {
+			return r, nil
+		}
+		if strings.HasPrefix(r.ID, ref) {
+			matches = append(matches, r)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("setup run %q not found", ref)
+	case 1:
+		return matches[0], nil
+	default:
+		return nil, fmt.Errorf("setup run %q is ambiguous (%d matches)", ref, len(matches))
+	}
+}
+
+// rollbackSetup runs the undo commands of a recorded run in reverse order
+func rollbackSetup(ref string) error {
+	if err := validateSetupFlags(); err != nil {
+		return err
+	}
+
+	run, err := findSetupRun(ref)
+	if err != nil {
+		return err
+	}
+
+	cyan := color.New(color.FgCyan, color.Bold)
+	green := color.New(color.FgGreen, color.Bold)
+	yellow := color.New(color.FgYellow, color.Bold)
+	magenta := color.New(color.FgMagenta, color.Bold)
+	red := color.New(color.FgRed, color.Bold)
+
+	printBanner(cyan, cyan, "║           ↩️  Setup Rollback                               ║")
+
+	fmt.Printf("\n📋 Task: %s\n", run.Task)
+	fmt.Printf("🕒 Run:  %s (%s)\n", run.ID, run.CreatedAt.Format(time.RFC1123))
+	if current := detectOS(); run.OS != "" && run.OS != current {
+		yellow.Printf("\n⚠️  This run was recorded on %s, but this system is %s\n", run.OS, current)
+	}
+
+	var kept []string
+	for i, step := range run.Steps {
+		if step.Ran() && step.Undo == "" {
+			kept = append(kept, fmt.Sprintf("%d. %s", i+1, step.Description))
+		}
+	}
+	if len(kept) > 0 {
+		fmt.Printf("\nℹ️  Steps without an undo command are left as they are: %s\n", strings.Join(kept, ", "))
+	}
+
+	indexes := run.undoable()
+	if len(indexes) == 0 {
+		yellow.Println("\n✓ Nothing to roll back.")
+		return nil
+	}
+
+	cyan.Println("\n📝 Rollback Plan:")
+	cyan.Println("─────────────────────────────────────────────────────────────")
+
+	steps := make([]SetupStep, len(indexes))
+	for n, i := range indexes {
+		step := run.Steps[i]
+		steps[n] = SetupStep{
+			Command:     step.Undo,
+			Description: fmt.Sprintf("Undo step %d: %s", i+1, step.Description),
+		}
+
+		fmt.Printf("\n%d. %s\n", n+1, steps[n].Description)
+		magenta.Printf("   Command: %s\n", step.Undo)
+		if step.Status == StepFailed {
+			yellow.Println("   The original step failed and may only have been partially applied")
+		}
+
+		assessment := classifyCommand(step.Undo)
+		printRiskAssessment(assessment, riskPolicy[assessment.Level], "   ")
+	}
+
+	cyan.Println("\n─────────────────────────────────────────────────────────────")
+
+	if !autoConfirm && !dryRun {
+		fmt.Print("\n❓ Do you want to roll back these steps? (yes/no): ")
+		reader := bufio.NewReader(os.Stdin)
+		response, _ := reader.ReadString('\n')
+		response = strings.TrimSpace(strings.ToLower(response))
+
+		if response != "yes" && response != "y" {
+			yellow.Println("\n❌ Rollback cancelled by user.")
+			return nil
+		}
+	}
+
+	if dryRun {
+		green.Println("\n✓ Dry run complete. No commands were executed.")
+		return nil
+	}
+
+	green.Println("\n\n↩️  Starting rollback...")
+
+	_, results := runSetupSteps(steps, aiAudit("rollback", run.Task), stepHooks{
+		onResult: func(_ []SetupStep, n int, result StepResult) {
+			if result.Status != StepSucceeded {
+				return
+			}
+			run.Steps[indexes[n]].RolledBack = true
+			if err := saveSetupRun(run); err != nil {
+				color.Red("Error recording rollback: %v", err)
+			}
+		},
+	})
+
+	var undone int
+	for _, result := range results {
+		if result.Status == StepSucceeded {
+			undone++
+		}
+	}
+
+	fmt.Println()
+	if undone == len(results) {
+		printBanner(cyan, green, "║           ✅ Rollback Complete!                           ║")
+	} else {
+		printBanner(cyan, red, "║           ⚠️  Rollback Incomplete                          ║")
+	}
+
+	fmt.Printf("\n↩️  Undone: %d of %d steps\n", undone, len(results))
+	if undone < len(results) {
+		yellow.Printf("💡 Run 'livecli setup --rollback %s' again to retry the remaining steps\n", run.ID)
+	}
+	fmt.Println()
+	return incompleteSteps(steps, results)
+}
@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var captureStderr bool
+
+var shellInitCmd = &cobra.Command{
+	Use:   "shell-init <bash|zsh|fish>",
+	Short: "Print the shell integration script for 'livecli fix' and the Ctrl+G widget",
+	Long: `Print a script that records every command you run in your shell, with its
+exit code, so 'livecli fix' can propose a corrected command when one fails.
+
+It also binds Ctrl+G to a widget that replaces what you typed at the prompt,
+e.g. "find big log files", with a command from 'livecli suggest'. The command
+is not run; review it and press Enter. To use another key, bind the function
+__livecli_widget yourself after the script.
+
+Add it to your shell's startup file:
+
+  bash (~/.bashrc):               eval "$(livecli shell-init bash)"
+  zsh (~/.zshrc):                 eval "$(livecli shell-init zsh)"
+  fish (~/.config/fish/config.fish): livecli shell-init fish | source
+
+With --capture-stderr (bash 4.4+ and zsh), the shell's error output is also
+copied to a temporary file so the model sees the error message. Programs then
+see stderr as a pipe rather than a terminal, which can turn off their colors
+or progress bars.`,
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: []string{"bash", "zsh", "fish"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		script, err := shellInitScript(args[0], captureStderr)
+		if err != nil {
+			return err
+		}
+		fmt.Print(script)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(shellInitCmd)
+	shellInitCmd.Flags().BoolVar(&captureStderr, "capture-stderr", false, "Also record the error output of commands (bash and zsh)")
+}
+
+// The widgets replace the prompt's buffer with the output of 'livecli suggest'
+// and leave it unchanged when that fails; its errors go to the terminal.
+
+const bashWidget = `__livecli_widget() {
+  local suggestion
+  [[ -n ${READLINE_LINE//[[:space:]]/} ]] || return 0
+  suggestion=$(command livecli suggest -- "$READLINE_LINE") || return 0
+  READLINE_LINE=$suggestion
+  READLINE_POINT=${#READLINE_LINE}
+}
+if [[ $- == *i* ]]; then
+  bind -m emacs -x '"\C-g": __livecli_widget'
+  bind -m vi-insert -x '"\C-g": __livecli_widget'
+fi
+`
+
+const zshWidget = `__livecli_widget() {
+  local suggestion
+  [[ -n ${BUFFER//[[:space:]]/} ]] || return 0
+  zle -I
+  suggestion=$(command livecli suggest -- "$BUFFER") || return 0
+  BUFFER=$suggestion
+  CURSOR=${#BUFFER}
+}
+zle -N __livecli_widget
+bindkey -M emacs '^G' __livecli_widget
+bindkey -M viins '^G' __livecli_widget
+`
+
+const fishWidget = `function __livecli_widget
+    set -l request (commandline)
+    if string trim -- "$request" | string length -q
+        set -l suggestion (command livecli suggest -- "$request" | string collect)
+        and commandline -r -- $suggestion
+    end
+    commandline -f repaint
+end
+bind \cg __livecli_widget
+bind -M insert \cg __livecli_widget 2>/dev/null
+`
+
+// The hooks export LIVECLI_LAST_COMMAND and LIVECLI_LAST_STATUS after every
+// command, except 'livecli fix' itself, so fix inherits them from the shell.
+// With stderr capture, the shell's stderr is teed into a file that is emptied
+// before each command and copied to LIVECLI_LAST_STDERR after it.
+
+const bashHook = `# livecli shell integration: records the last command for 'livecli fix'
+__livecli_precmd() {
+  local exit_status=$? command
+  # "  42  cmd", or "  42* cmd" when the entry was edited
+  command=$(HISTTIMEFORMAT= builtin history 1)
+  command=${command#"${command%%[0-9]*}"}
+  command=${command#"${command%%[!0-9]*}"}
+  command=${command:2}
+  case $command in
+    "" | "livecli fix"*) ;;
+    *)
+      export LIVECLI_LAST_COMMAND=$command LIVECLI_LAST_STATUS=$exit_status
+      __livecli_save_stderr
+      ;;
+  esac
+  return $exit_status
+}
+__livecli_save_stderr() { :; }
+if [[ ";${PROMPT_COMMAND[*]};" != *";__livecli_precmd;"* ]]; then
+  PROMPT_COMMAND="__livecli_precmd${PROMPT_COMMAND:+;$PROMPT_COMMAND}"
+fi
+`
+
+const bashCapture = `if [[ -z $__livecli_stderr_capture ]]; then
+  __livecli_stderr_capture=$(mktemp "${TMPDIR:-/tmp}/livecli-stderr.XXXXXX")
+  export LIVECLI_LAST_STDERR=$__livecli_stderr_capture.last
+  exec 2> >(tee -a "$__livecli_stderr_capture" >&2)
+  # PS0 is expanded after a command is read, just before it runs
+  PS0="${PS0}"'$(: >| "$__livecli_stderr_capture")'
+  [[ -z $(trap -p EXIT) ]] && trap 'command rm -f -- "$__livecli_stderr_capture" "$LIVECLI_LAST_STDERR"' EXIT
+fi
+__livecli_save_stderr() {
+  command cat -- "$__livecli_stderr_capture" >| "$LIVECLI_LAST_STDERR" 2>/dev/null
+}
+`
+
+const zshHook = `# livecli shell integration: records the last command for 'livecli fix'
+__livecli_preexec() {
+  __livecli_command=$1
+  __livecli_clear_stderr
+}
+__livecli_precmd() {
+  local exit_status=$?
+  [[ -n $__livecli_command ]] || return 0
+  case $__livecli_command in
+    ("livecli fix"*) ;;
+    (*)
+      export LIVECLI_LAST_COMMAND=$__livecli_command LIVECLI_LAST_STATUS=$exit_status
+      __livecli_save_stderr
+      ;;
+  esac
+  __livecli_command=
+}
+__livecli_clear_stderr() { :; }
+__livecli_save_stderr() { :; }
+autoload -Uz add-zsh-hook
+add-zsh-hook preexec __livecli_preexec
+add-zsh-hook precmd __livecli_precmd
+`
+
+const zshCapture = `if [[ -z $__livecli_stderr_capture ]]; then
+  __livecli_stderr_capture=$(mktemp "${TMPDIR:-/tmp}/livecli-stderr.XXXXXX")
+  export LIVECLI_LAST_STDERR=$__livecli_stderr_capture.last
+  exec 2> >(tee -a "$__livecli_stderr_capture" >&2)
+  __livecli_cleanup() { command rm -f -- "$__livecli_stderr_capture" "$LIVECLI_LAST_STDERR"; }
+  add-zsh-hook zshexit __livecli_cleanup
+fi
+__livecli_clear_stderr() { : >| "$__livecli_stderr_capture"; }
+__livecli_save_stderr() {
+  command cat -- "$__livecli_stderr_capture" >| "$LIVECLI_LAST_STDERR" 2>/dev/null
+}
+`
+
+const fishHook = `# livecli shell integration: records the last command for 'livecli fix'
+function __livecli_postexec --on-event fish_postexec
+    set -l exit_status $status
+    test -n "$argv[1]"; or return
+    string match -q -- 'livecli fix*' $argv[1]; and return
+    set -gx LIVECLI_LAST_COMMAND $argv[1]
+    set -gx LIVECLI_LAST_STATUS $exit_status
+end
+`
+
+// shellInitScript returns the integration script for a shell
+func shellInitScript(shell string, capture bool) (string, error) {
+	var parts []string
+	switch shell {
+	case "bash":
+		parts = append(parts, bashHook, bashWidget)
+		if capture {
+			parts = append(parts, bashCapture)
+		}
+	case "zsh":
+		parts = append(parts, zshHook, zshWidget)
+		if capture {
+			parts = append(parts, zshCapture)
+		}
+	case "fish":
+		if capture {
+			return "", fmt.Errorf("--capture-stderr is not supported for fish")
+		}
+		parts = append(parts, fishHook, fishWidget)
+	default:
+		return "", fmt.Errorf("unsupported shell %q (use bash, zsh or fish)", shell)
+	}
+	return strings.Join(parts, ""), nil
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+)
+
+// errResponseCancelled is returned when the user interrupts an in-flight response
+var errResponseCancelled = errors.New("response cancelled")
+
+// streamResponse prints a completion to stdout as it arrives. Ctrl+C cancels
+// only this response, so the surrounding session keeps running.
+func streamResponse(provider Provider, req ChatRequest) (ChatResponse, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+
+	go func() {
+		select {
+		case <-interrupt:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	var resp ChatResponse
+	var err error
+	if noStream {
+		resp, err = provider.Chat(ctx, req)
+		if err == nil {
+			fmt.Print(resp.Content)
+		}
+	} else {
+		resp, err = provider.ChatStream(ctx, req, func(token string) {
+			fmt.Print(token)
+		})
+	}
+
+	if err != nil && errors.Is(ctx.Err(), context.Canceled) {
+		return resp, errResponseCancelled
+	}
+	return resp, err
+}
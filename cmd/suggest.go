@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var suggestCmd = &cobra.Command{
+	Use:   "suggest <request>",
+	Short: "Print a shell command for a request, without running it",
+	Long: `Turn a sentence into a single shell command, like 'do', and print only the
+command, for scripts and the shell widget set up by 'livecli shell-init'.
+Nothing is run and nothing is asked. Errors go to stderr with a non-zero exit
+code, and commands the risk policy blocks are refused.
+
+With --output json, {request, command, explanation, risk} is printed instead.
+
+Examples:
+  livecli suggest "show disk usage of each directory here, largest first"
+  cmd=$(livecli suggest "count lines of Go code") && echo "$cmd"`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Only the command may reach stdout, not retry warnings
+		divertStdout(cmd)
+		return suggestCommand(strings.Join(args, " "))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(suggestCmd)
+	suggestCmd.Flags().StringVar(&riskSpec, "risk-policy", "", "Override the action per risk level, e.g. critical=block")
+}
+
+// SuggestResult is the JSON output of suggest
+type SuggestResult struct {
+	Request     string `json:"request"`
+	Command     string `json:"command"`
+	Explanation string `json:"explanation"`
+	Risk        string `json:"risk"`
+}
+
+func suggestCommand(request string) error {
+	if err := validateSetupFlags(); err != nil {
+		return err
+	}
+
+	provider, err := newProvider()
+	if err != nil {
+		return err
+	}
+
+	suggestion, err := generateCommand(provider, request)
+	if err != nil {
+		return err
+	}
+
+	assessment := classifyCommand(suggestion.Command)
+	if riskPolicy[assessment.Level] == RiskBlock {
+		return fmt.Errorf("the suggested command is blocked by the risk policy (%s risk): %s",
+			assessment.Level, suggestion.Command)
+	}
+
+	if jsonOutput() {
+		writeJSON(SuggestResult{
+			Request:     request,
+			Command:     suggestion.Command,
+			Explanation: suggestion.Explanation,
+			Risk:        assessment.Level.String(),
+		})
+		return nil
+	}
+
+	fmt.Fprintln(resultOut, suggestion.Command)
+	return nil
+}
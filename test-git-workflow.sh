@@ -0,0 +1,147 @@
+#!/bin/bash
+
+# LiveCLI - Git Workflow Safety Tests
+# Runs `livecli git` with hostile commit messages against a throwaway
+# repository and checks that every message is committed verbatim and that
+# no shell syntax inside a message is ever executed.
+
+set -e
+
+echo "╔═══════════════════════════════════════════════════════════╗"
+echo "║        LiveCLI - Git Workflow Safety Tests                ║"
+echo "╚═══════════════════════════════════════════════════════════╝"
+echo ""
+
+# Colors
+GREEN='\033[0;32m'
+RED='\033[0;31m'
+YELLOW='\033[1;33m'
+NC='\033[0m' # No Color
+
+# Test counter
+TESTS_PASSED=0
+TESTS_FAILED=0
+
+# Helper functions
+pass() {
+    echo -e "${GREEN}✓${NC} $1"
+    TESTS_PASSED=$((TESTS_PASSED + 1))
+}
+
+fail() {
+    echo -e "${RED}✗${NC} $1"
+    TESTS_FAILED=$((TESTS_FAILED + 1))
+}
+
+info() {
+    echo -e "${YELLOW}ℹ${NC} $1"
+}
+
+# Always rebuild so that the tests never run a stale binary
+LIVECLI="$(pwd)/livecli"
+echo "Building livecli..."
+go build -o "$LIVECLI" .
+
+# Temporary repository with a bare remote so that `git push` succeeds
+WORK_DIR=$(mktemp -d)
+trap 'rm -rf "$WORK_DIR"' EXIT
+
+REMOTE="$WORK_DIR/remote.git"
+REPO="$WORK_DIR/repo"
+CANARY="$WORK_DIR/pwned"
+
+git init -q --bare "$REMOTE"
+git init -q "$REPO"
+cd "$REPO"
+git config user.name "LiveCLI Test"
+git config user.email "test@example.com"
+git config commit.gpgsign false
+echo "start" > file.txt
+git add file.txt
+git commit -q -m "initial commit"
+git remote add origin "$REMOTE"
+git push -q -u origin HEAD 2>/dev/null
+
+info "Test repository: $REPO"
+
+# commit_and_check runs the workflow with a message and verifies the result
+commit_and_check() {
+    local name="$1"
+    local message="$2"
+
+    echo "$name $RANDOM" >> file.txt
+    rm -f "$CANARY"
+
+    if ! "$LIVECLI" git --yes -- "$message" > "$WORK_DIR/output.txt" 2>&1; then
+        fail "$name: livecli git exited with an error"
+        return
+    fi
+
+    if [ -e "$CANARY" ]; then
+        fail "$name: shell syntax in the message was executed"
+        return
+    fi
+
+    local committed
+    committed=$(git log -1 --format=%B)
+    if [ "$committed" != "$message" ]; then
+        fail "$name: committed message differs"
+        echo "    expected: $message"
+        echo "    got:      $committed"
+        return
+    fi
+
+    if [ "$(git rev-parse HEAD)" != "$(git rev-parse '@{u}')" ]; then
+        fail "$name: commit was not pushed"
+        return
+    fi
+
+    pass "$name"
+}
+
+echo ""
+echo "Running hostile commit messages..."
+
+commit_and_check "Double quotes" 'fix: handle "quoted" values'
+commit_and_check "Single quotes" "fix: don't break on 'single' quotes"
+commit_and_check "Command substitution" "feat: \$(touch $CANARY)"
+commit_and_check "Backticks" "feat: \`touch $CANARY\`"
+commit_and_check "Command separator" "chore: done; touch $CANARY"
+commit_and_check "Pipes and redirects" "chore: a | touch $CANARY > /dev/null && touch $CANARY"
+commit_and_check "Variable expansion" 'docs: mention $HOME and ${PATH}'
+commit_and_check "Leading dash" "--amend"
+commit_and_check "Backslashes" 'fix: escape \n and \\ correctly'
+commit_and_check "Multi-line message" "feat: add multi-line support
+
+This body spans several lines.
+It mentions \$(touch $CANARY) too."
+commit_and_check "Comment-like line" "fix: keep lines
+
+# this line starts with a hash"
+commit_and_check "Unicode" "docs: add émojis 🚀 and ünïcödé"
+
+echo ""
+echo "Checking the exit status of a failed workflow..."
+
+# A push to a missing remote must make livecli itself exit non-zero
+echo "push failure $RANDOM" >> file.txt
+git remote set-url origin "$WORK_DIR/missing.git"
+if "$LIVECLI" git --yes -- "fix: push nowhere" > "$WORK_DIR/output.txt" 2>&1; then
+    fail "Failed push: livecli git exited with status 0"
+else
+    pass "Failed push: livecli git exited with status $?"
+fi
+git remote set-url origin "$REMOTE"
+
+# Summary
+echo ""
+echo "╔═══════════════════════════════════════════════════════════╗"
+echo "║                    Test Summary                            ║"
+echo "╚═══════════════════════════════════════════════════════════╝"
+echo ""
+echo -e "${GREEN}Tests Passed:${NC} $TESTS_PASSED"
+echo -e "${RED}Tests Failed:${NC} $TESTS_FAILED"
+
+if [ $TESTS_FAILED -ne 0 ]; then
+    exit 1
+fi